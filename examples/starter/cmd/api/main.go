@@ -226,5 +226,8 @@ func main() {
 	fmt.Fprint(w, logger.DefaultLogger.SInfof(""))
 	w.Flush()
 
-	s.Run(context.Background())
+	if err := s.Run(context.Background()); err != nil {
+		logger.DefaultLogger.Errorf("server stopped with error: %+v", err)
+		os.Exit(1)
+	}
 }
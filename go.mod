@@ -8,6 +8,7 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/lib/pq v1.10.9
+	golang.org/x/net v0.27.0
 	golang.org/x/tools v0.23.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -15,4 +16,5 @@ require (
 require (
 	golang.org/x/mod v0.19.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 )
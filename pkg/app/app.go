@@ -2,8 +2,13 @@ package app
 
 import (
 	gocontext "context"
+	"crypto/tls"
+	"errors"
+	"net"
 	"net/http"
-	"time"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/version-1/gooo/pkg/config"
 	"github.com/version-1/gooo/pkg/context"
@@ -11,14 +16,22 @@ import (
 	"github.com/version-1/gooo/pkg/http/request"
 	"github.com/version-1/gooo/pkg/http/response"
 	"github.com/version-1/gooo/pkg/logger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 type Server struct {
-	Addr         string
-	Config       *config.App
+	Addr   string
+	Config *config.App
+	// ErrorHandler is called when a handler panics. The default 500 response
+	// is rendered unless it writes a response.
 	ErrorHandler func(w *response.Response, r *request.Request, e error)
 	Handlers     []controller.Handler
-	Middlewares  []controller.Middleware
+	Middlewares  controller.Middlewares
+
+	startHooks    []Hook
+	shutdownHooks []Hook
+	panicHooks    []controller.PanicHook
 }
 
 func (s *Server) SetLogger(l logger.Logger) {
@@ -34,7 +47,7 @@ func (s *Server) RegisterHandlers(h ...controller.Handler) {
 }
 
 func (s *Server) RegisterMiddlewares(m ...controller.Middleware) {
-	s.Middlewares = append(s.Middlewares, m...)
+	s.Middlewares.Append(m...)
 }
 
 func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -45,18 +58,21 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w,
 		response.Options{
 			Adapter: string(s.Config.DefaultResponseRenderer),
+			Logger:  s.Logger(),
 		},
 	)
 
-	for _, m := range s.Middlewares {
-		if m.If(rr) {
-			s.withRecover(m.String(), ww, rr, func() {
-				if next := m.Do(ww, rr); !next {
-					return
-				}
-			})
-		}
+	h := s.Middlewares.Then(func(*response.Response, *request.Request) {})
+	controller.Recover(s.Logger(), s.recoverHooks()...).Apply(h)(ww, rr)
+}
+
+func (s Server) recoverHooks() []controller.PanicHook {
+	hooks := s.panicHooks
+	if s.ErrorHandler != nil {
+		hooks = append(hooks[:len(hooks):len(hooks)], s.ErrorHandler)
 	}
+
+	return hooks
 }
 
 func WithDefaultMiddlewares(s *Server) {
@@ -69,46 +85,172 @@ func WithDefaultMiddlewares(s *Server) {
 				return r.WithContext(ctx)
 			},
 		),
+		controller.RequestID(),
 		controller.RequestLogger(s.Logger()),
+		controller.ResponseLogger(s.Logger()),
+		controller.ContentNegotiation(),
 		controller.RequestBodyLogger(s.Logger()),
 		controller.RequestHandler(s.Handlers),
-		controller.ResponseLogger(s.Logger()),
 	)
 }
 
-func (s Server) Run(ctx gocontext.Context) {
-	hs := &http.Server{
-		Addr:           s.Addr,
-		Handler:        s,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		MaxHeaderBytes: 1 << 20,
+// Hook is called on server lifecycle events.
+type Hook func(ctx gocontext.Context) error
+
+func (s *Server) OnStart(h ...Hook) {
+	s.startHooks = append(s.startHooks, h...)
+}
+
+func (s *Server) OnShutdown(h ...Hook) {
+	s.shutdownHooks = append(s.shutdownHooks, h...)
+}
+
+// OnPanic registers hooks called when a handler panics.
+func (s *Server) OnPanic(h ...controller.PanicHook) {
+	s.panicHooks = append(s.panicHooks, h...)
+}
+
+// Run serves until ctx is canceled or the process receives SIGINT or SIGTERM,
+// then drains in-flight requests within Config.ShutdownTimeout.
+func (s Server) Run(ctx gocontext.Context) error {
+	return s.listenAndServe(ctx, "tcp", addrOr(s.Addr, ":http"), nil)
+}
+
+// RunTLS serves HTTPS with the certificate and key files, reloading them when
+// they change on disk.
+func (s Server) RunTLS(ctx gocontext.Context, certFile, keyFile string) error {
+	r, err := newCertReloader(certFile, keyFile, s.Logger())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := gocontext.WithCancel(ctx)
+	defer cancel()
+	go r.watch(ctx, s.Config.GetCertReloadInterval())
+
+	return s.RunTLSConfig(ctx, &tls.Config{GetCertificate: r.GetCertificate})
+}
+
+// RunTLSConfig serves HTTPS with an in-memory tls.Config. HTTP/2 is negotiated
+// through ALPN.
+func (s Server) RunTLSConfig(ctx gocontext.Context, cfg *tls.Config) error {
+	return s.listenAndServe(ctx, "tcp", addrOr(s.Addr, ":https"), cfg)
+}
+
+// RunUnix serves on a unix domain socket.
+func (s Server) RunUnix(ctx gocontext.Context, path string) error {
+	return s.listenAndServe(ctx, "unix", path, nil)
+}
+
+// Serve serves on the caller-supplied listener and closes it on shutdown.
+func (s Server) Serve(ctx gocontext.Context, l net.Listener) error {
+	hs := s.httpServer()
+
+	return s.serve(ctx, hs, func() error {
+		return s.serveListener(hs, l)
+	})
+}
+
+func (s Server) listenAndServe(ctx gocontext.Context, network, addr string, cfg *tls.Config) error {
+	hs := s.httpServer()
+	hs.TLSConfig = cfg
+
+	return s.serve(ctx, hs, func() error {
+		l, err := net.Listen(network, addr)
+		if err != nil {
+			return err
+		}
+
+		return s.serveListener(hs, l)
+	})
+}
+
+func (s Server) serveListener(hs *http.Server, l net.Listener) error {
+	s.Logger().Infof("Server is running on %s", l.Addr())
+	if hs.TLSConfig != nil {
+		return hs.ServeTLS(l, "", "")
 	}
 
+	return hs.Serve(l)
+}
+
+func (s Server) httpServer() *http.Server {
 	if len(s.Handlers) == 0 {
 		panic("No handlers registered")
 	}
-	defer hs.Shutdown(ctx)
 
-	s.Logger().Infof("Server is running on %s", s.Addr)
-	hs.ListenAndServe()
+	var handler http.Handler = s
+	if s.Config.EnableH2C {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	return &http.Server{
+		Addr:           s.Addr,
+		Handler:        handler,
+		ReadTimeout:    s.Config.GetReadTimeout(),
+		WriteTimeout:   s.Config.GetWriteTimeout(),
+		IdleTimeout:    s.Config.GetIdleTimeout(),
+		MaxHeaderBytes: 1 << 20,
+	}
 }
 
-func (s Server) WalkThrough(cb func(h controller.Handler)) {
-	for _, h := range s.Handlers {
-		cb(h)
+func addrOr(addr, fallback string) string {
+	if addr == "" {
+		return fallback
 	}
+
+	return addr
 }
 
-func (s Server) withRecover(spot string, w *response.Response, r *request.Request, fn func()) {
-	defer func() {
-		if e := recover(); e != nil {
-			s.Logger().Errorf("Caught panic on %s", spot)
-			if err, ok := e.(error); ok {
-				s.ErrorHandler(w, r, err)
-			}
+func (s Server) serve(ctx gocontext.Context, hs *http.Server, listen func() error) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, h := range s.startHooks {
+		if err := h(ctx); err != nil {
+			return err
 		}
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listen()
 	}()
 
-	fn()
+	var err error
+	select {
+	case err = <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+	case <-ctx.Done():
+		s.Logger().Infof("Server is shutting down")
+	}
+	stop()
+
+	return errors.Join(err, s.shutdown(hs))
+}
+
+func (s Server) shutdown(hs *http.Server) error {
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), s.Config.GetShutdownTimeout())
+	defer cancel()
+
+	errs := []error{}
+	if err := hs.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+		if err := s.shutdownHooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s Server) WalkThrough(cb func(h controller.Handler)) {
+	for _, h := range s.Handlers {
+		cb(h)
+	}
 }
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/version-1/gooo/pkg/config"
+	"github.com/version-1/gooo/pkg/controller"
+	"github.com/version-1/gooo/pkg/http/request"
+	"github.com/version-1/gooo/pkg/http/response"
+	"golang.org/x/net/http2"
+)
+
+func newTestServer(addr string) *Server {
+	return &Server{
+		Addr:   addr,
+		Config: &config.App{ShutdownTimeout: time.Second},
+		Handlers: []controller.Handler{
+			controller.Get("/ping", func(w *response.Response, r *request.Request) {
+				w.WriteHeader(http.StatusOK)
+			}),
+		},
+	}
+}
+
+func TestServerRunLifecycle(t *testing.T) {
+	s := newTestServer("127.0.0.1:0")
+
+	events := []string{}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.OnStart(func(ctx context.Context) error {
+		events = append(events, "start")
+		cancel()
+		return nil
+	})
+	s.OnShutdown(
+		func(ctx context.Context) error {
+			events = append(events, "shutdown1")
+			return nil
+		},
+		func(ctx context.Context) error {
+			events = append(events, "shutdown2")
+			return nil
+		},
+	)
+
+	if err := s.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"start", "shutdown2", "shutdown1"}
+	if !reflect.DeepEqual(events, expect) {
+		t.Errorf("expect %v, got %v", expect, events)
+	}
+}
+
+func TestServerRunListenError(t *testing.T) {
+	s := newTestServer("invalid-address")
+
+	shutdown := false
+	s.OnShutdown(func(ctx context.Context) error {
+		shutdown = true
+		return nil
+	})
+
+	if err := s.Run(context.Background()); err == nil {
+		t.Fatal("expected listen error")
+	}
+
+	if !shutdown {
+		t.Error("expected shutdown hooks to run after listen error")
+	}
+}
+
+func TestServerServeDrainsInFlightRequests(t *testing.T) {
+	s := newTestServer("")
+	started := make(chan struct{})
+	s.RegisterHandlers(controller.Get("/slow", func(w *response.Response, r *request.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	s.Middlewares.Append(controller.RequestHandler(s.Handlers))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, l)
+	}()
+
+	resCh := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.Get(fmt.Sprintf("http://%s/slow", l.Addr()))
+		if err != nil {
+			t.Error(err)
+		}
+		resCh <- res
+	}()
+
+	<-started
+	cancel()
+
+	res := <-resCh
+	if res == nil {
+		t.Fatal("expected in-flight request to complete")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		t.Errorf("expected status %d, got %d", http.StatusAccepted, res.StatusCode)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerServeH2C(t *testing.T) {
+	s := newTestServer("")
+	s.Config.EnableH2C = true
+	s.Middlewares.Append(controller.RequestHandler(s.Handlers))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Serve(ctx, l)
+
+	client := http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	res, err := client.Get(fmt.Sprintf("http://%s/ping", l.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", res.Proto)
+	}
+}
+
+func TestServerRecoverPanic(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		handler func(w *response.Response, r *request.Request, err error)
+		status  int
+	}{
+		{name: "string", value: "boom", status: http.StatusInternalServerError},
+		{name: "error", value: errors.New("boom"), status: http.StatusInternalServerError},
+		{
+			name:  "error handler writes response",
+			value: "boom",
+			handler: func(w *response.Response, r *request.Request, err error) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			status: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestServer("")
+			s.Config.DefaultResponseRenderer = config.JSONAPIRenderer
+			s.ErrorHandler = test.handler
+			s.RegisterHandlers(controller.Get("/panic", func(w *response.Response, r *request.Request) {
+				panic(test.value)
+			}))
+			s.Middlewares.Append(controller.RequestHandler(s.Handlers))
+
+			var recovered error
+			s.OnPanic(func(w *response.Response, r *request.Request, err error) {
+				recovered = err
+			})
+
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+			if rec.Code != test.status {
+				t.Errorf("expected status %d, got %d", test.status, rec.Code)
+			}
+
+			if recovered == nil || !strings.Contains(recovered.Error(), "boom") {
+				t.Errorf("expected hook to receive the panic, got %v", recovered)
+			}
+
+			if test.handler == nil && !strings.Contains(rec.Body.String(), `"errors"`) {
+				t.Errorf("expected errors document, got %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	gocontext "context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+	"github.com/version-1/gooo/pkg/logger"
+)
+
+// certReloader keeps a certificate loaded from files and swaps it when the
+// files are modified, so certificates can be rotated without a restart.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   logger.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertReloader(certFile, keyFile string, l logger.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   l,
+	}
+
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// reload loads the certificate when the files are newer than the loaded one.
+func (r *certReloader) reload() (bool, error) {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	loaded, current := r.cert != nil, r.modTime
+	r.mu.RUnlock()
+	if loaded && !modTime.After(current) {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, goooerrors.Wrap(err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(f)
+		if err != nil {
+			return latest, goooerrors.Wrap(err)
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+func (r *certReloader) watch(ctx gocontext.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				r.logger.Errorf("failed to reload certificate. keep serving the current one: %s", err)
+				continue
+			}
+
+			if reloaded {
+				r.logger.Infof("Reloaded certificate %s", r.certFile)
+			}
+		}
+	}
+}
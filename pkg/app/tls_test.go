@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/version-1/gooo/pkg/controller"
+	"github.com/version-1/gooo/pkg/logger"
+)
+
+func writeCert(t *testing.T, dir string, cn string, modTime time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, r *certReloader) string {
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	certFile, keyFile := writeCert(t, dir, "first", now.Add(-time.Minute))
+
+	r, err := newCertReloader(certFile, keyFile, logger.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cn := commonName(t, r); cn != "first" {
+		t.Fatalf("expected first certificate, got %s", cn)
+	}
+
+	if reloaded, err := r.reload(); err != nil || reloaded {
+		t.Fatalf("expected no reload without changes. reloaded: %t, err: %v", reloaded, err)
+	}
+
+	writeCert(t, dir, "second", now)
+	if reloaded, err := r.reload(); err != nil || !reloaded {
+		t.Fatalf("expected reload after changes. reloaded: %t, err: %v", reloaded, err)
+	}
+
+	if cn := commonName(t, r); cn != "second" {
+		t.Errorf("expected second certificate, got %s", cn)
+	}
+}
+
+func TestServerServeTLS(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir(), "gooo", time.Now())
+	r, err := newCertReloader(certFile, keyFile, logger.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestServer("")
+	s.Middlewares.Append(controller.RequestHandler(s.Handlers))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = tls.NewListener(l, &tls.Config{
+		GetCertificate: r.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Serve(ctx, l)
+
+	client := http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		},
+	}
+
+	res, err := client.Get(fmt.Sprintf("https://%s/ping", l.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	if res.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", res.Proto)
+	}
+}
@@ -14,6 +14,8 @@ type SeedExecutor struct {
 	cfg Config
 }
 
+var _ Logger = logger.Logger(nil)
+
 type Logger interface {
 	Infof(format string, args ...any)
 	Warnf(format string, args ...any)
@@ -33,7 +35,7 @@ func New(config Config) *SeedExecutor {
 	}
 }
 
-func (s SeedExecutor) logger() logger.Logger {
+func (s SeedExecutor) logger() Logger {
 	return s.cfg.Logger()
 }
 
@@ -1,18 +1,38 @@
 package config
 
 import (
+	"time"
+
 	"github.com/version-1/gooo/pkg/logger"
 )
 
+const (
+	DefaultReadTimeout        = 10 * time.Second
+	DefaultWriteTimeout       = 10 * time.Second
+	DefaultIdleTimeout        = 60 * time.Second
+	DefaultShutdownTimeout    = 10 * time.Second
+	DefaultCertReloadInterval = 10 * time.Second
+)
+
 type App struct {
 	Logger                  logger.Logger
 	DefaultResponseRenderer ResponseRenderer
+	ReadTimeout             time.Duration
+	WriteTimeout            time.Duration
+	IdleTimeout             time.Duration
+	// ShutdownTimeout is the deadline to drain in-flight requests on shutdown.
+	ShutdownTimeout time.Duration
+	// CertReloadInterval is how often certificate files are checked for changes.
+	CertReloadInterval time.Duration
+	// EnableH2C serves HTTP/2 over cleartext connections in addition to HTTP/1.1.
+	EnableH2C bool
 }
 
 type ResponseRenderer string
 
 const (
 	JSONAPIRenderer ResponseRenderer = "jsonapi"
+	JSONRenderer    ResponseRenderer = "json"
 	RawRenderer     ResponseRenderer = "raw"
 )
 
@@ -23,3 +43,31 @@ func (c App) GetLogger() logger.Logger {
 
 	return c.Logger
 }
+
+func (c App) GetReadTimeout() time.Duration {
+	return durationOr(c.ReadTimeout, DefaultReadTimeout)
+}
+
+func (c App) GetWriteTimeout() time.Duration {
+	return durationOr(c.WriteTimeout, DefaultWriteTimeout)
+}
+
+func (c App) GetIdleTimeout() time.Duration {
+	return durationOr(c.IdleTimeout, DefaultIdleTimeout)
+}
+
+func (c App) GetShutdownTimeout() time.Duration {
+	return durationOr(c.ShutdownTimeout, DefaultShutdownTimeout)
+}
+
+func (c App) GetCertReloadInterval() time.Duration {
+	return durationOr(c.CertReloadInterval, DefaultCertReloadInterval)
+}
+
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+
+	return d
+}
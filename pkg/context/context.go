@@ -2,19 +2,33 @@ package context
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/version-1/gooo/pkg/config"
+	"github.com/version-1/gooo/pkg/logger"
 )
 
 const (
 	APP_CONFIG_KEY  = "gooo:request:app_config"
 	USER_CONFIG_KEY = "gooo:request:user_config"
+	REQUEST_ID_KEY  = "gooo:request:request_id"
+	TX_KEY          = "gooo:request:tx"
+	PRIMARY_KEY     = "gooo:request:primary"
 )
 
+const RequestIDHeader = "X-Request-ID"
+
 func Get[T any](ctx context.Context, key string) T {
 	return ctx.Value(key).(T)
 }
 
+// Lookup is the same as Get but reports whether the value is set instead of
+// panicking.
+func Lookup[T any](ctx context.Context, key string) (T, bool) {
+	v, ok := ctx.Value(key).(T)
+	return v, ok
+}
+
 func With[T any](ctx context.Context, key string, value T) context.Context {
 	return context.WithValue(ctx, key, value)
 }
@@ -34,3 +48,64 @@ func WithUserConfig[T any](ctx context.Context, u T) context.Context {
 func UserConfig[T any](ctx context.Context) T {
 	return Get[T](ctx, USER_CONFIG_KEY)
 }
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return With(ctx, REQUEST_ID_KEY, id)
+}
+
+// RequestID returns the request id or an empty string when it is not set.
+func RequestID(ctx context.Context) string {
+	id, _ := Lookup[string](ctx, REQUEST_ID_KEY)
+	return id
+}
+
+// Logger returns the app logger scoped to the request.
+func Logger(ctx context.Context) logger.Logger {
+	var l logger.Logger = logger.DefaultLogger
+	if cfg, ok := Lookup[*config.App](ctx, APP_CONFIG_KEY); ok && cfg != nil {
+		l = cfg.GetLogger()
+	}
+
+	return ScopedLogger(ctx, l)
+}
+
+// ScopedLogger adds the request id of ctx to every entry of l.
+func ScopedLogger(ctx context.Context, l logger.Logger) logger.Logger {
+	if id := RequestID(ctx); id != "" {
+		return l.With("request_id", id)
+	}
+
+	return l
+}
+
+// Transaction is a transaction carried by a context, e.g. a db.Tx or an
+// *orm.Executor.
+type Transaction interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Commit() error
+	Rollback() error
+}
+
+func WithTx(ctx context.Context, tx Transaction) context.Context {
+	return With(ctx, TX_KEY, tx)
+}
+
+// Tx returns the transaction of ctx and reports whether it is set.
+func Tx(ctx context.Context) (Transaction, bool) {
+	tx, ok := Lookup[Transaction](ctx, TX_KEY)
+	return tx, ok && tx != nil
+}
+
+// WithPrimary makes db.Router read from the primary, e.g. to read data
+// written earlier in the request.
+func WithPrimary(ctx context.Context) context.Context {
+	return With(ctx, PRIMARY_KEY, true)
+}
+
+// Primary reports whether reads must go to the primary.
+func Primary(ctx context.Context) bool {
+	v, _ := Lookup[bool](ctx, PRIMARY_KEY)
+	return v
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
-	"strings"
 
 	"github.com/version-1/gooo/pkg/http/request"
 	"github.com/version-1/gooo/pkg/http/response"
@@ -13,30 +12,81 @@ import (
 type BeforeHandlerFunc func(*response.Response, *request.Request) bool
 type HandlerFunc func(*response.Response, *request.Request)
 
+// GroupHandler mounts handlers and nested groups under a common path. Its
+// middlewares wrap every handler of the group, including nested groups.
 type GroupHandler struct {
-	Path     string
-	Handlers []Handler
+	Path        string
+	Handlers    []Handler
+	Groups      []GroupHandler
+	Middlewares Middlewares
 }
 
 func (g *GroupHandler) Add(h ...Handler) {
 	g.Handlers = append(g.Handlers, h...)
 }
 
+func (g *GroupHandler) AddGroup(groups ...GroupHandler) {
+	g.Groups = append(g.Groups, groups...)
+}
+
+func (g *GroupHandler) Use(mw ...Middleware) {
+	g.Middlewares.Append(mw...)
+}
+
 func (g GroupHandler) List() []Handler {
-	list := make([]Handler, len(g.Handlers))
-	for i, h := range g.Handlers {
-		h.Path = filepath.Clean(g.Path + h.Path)
-		list[i] = h
+	list := make([]Handler, 0, len(g.Handlers))
+	for _, h := range g.Handlers {
+		list = append(list, g.mount(h))
+	}
+
+	for _, sub := range g.Groups {
+		for _, h := range sub.List() {
+			list = append(list, g.mount(h))
+		}
 	}
 
 	return list
 }
 
+func (g GroupHandler) mount(h Handler) Handler {
+	h.Path = filepath.Clean(g.Path + h.Path)
+
+	mw := make(Middlewares, 0, len(g.Middlewares)+len(h.Middlewares))
+	mw = append(mw, g.Middlewares...)
+	h.Middlewares = append(mw, h.Middlewares...)
+
+	return h
+}
+
 type Handler struct {
 	Path          string
 	Method        string
 	BeforeHandler *BeforeHandlerFunc
 	Handler       HandlerFunc
+	Middlewares   Middlewares
+}
+
+// With returns a copy of the handler wrapped by the given middlewares.
+func (h Handler) With(mw ...Middleware) Handler {
+	list := make(Middlewares, 0, len(h.Middlewares)+len(mw))
+	list = append(list, h.Middlewares...)
+	h.Middlewares = append(list, mw...)
+
+	return h
+}
+
+// Chain composes the handler with its middlewares. BeforeHandler runs last,
+// right before the handler, and stops the chain when it returns false.
+func (h Handler) Chain() HandlerFunc {
+	mw := h.Middlewares
+	if h.BeforeHandler != nil {
+		mw = append(append(Middlewares{}, mw...), Middleware{
+			Name: "BeforeHandler",
+			Do:   *h.BeforeHandler,
+		})
+	}
+
+	return mw.Then(h.Handler)
 }
 
 func (h Handler) String() string {
@@ -48,50 +98,18 @@ func (h Handler) Match(r *request.Request) bool {
 		return false
 	}
 
-	if r.Request.URL.Path == h.Path {
-		return true
-	}
-
-	parts := strings.Split(h.Path, "/")
-	targetParts := strings.Split(r.Request.URL.Path, "/")
-	if len(parts) < len(targetParts) {
-		return false
-	}
-
-	for i, part := range parts {
-		if !strings.HasPrefix(part, ":") && part != targetParts[i] {
-			return false
-		}
-	}
-
-	return false
+	_, ok := matchPath(h.Path, r.Request.URL.Path)
+	return ok
 }
 
 func (h Handler) Param(url string, key string) (string, bool) {
-	search := ":" + key
-	if !strings.Contains(h.Path, search) {
-		return "", false
-	}
-
-	parts := strings.Split(h.Path, "/")
-	index := -1
-	for i, part := range parts {
-		if part == search {
-			index = i
-			break
-		}
-	}
-
-	if index == -1 {
-		return "", false
-	}
-
-	targetParts := strings.Split(url, "/")
-	if len(targetParts) < index {
+	params, ok := matchPath(h.Path, url)
+	if !ok {
 		return "", false
 	}
 
-	return targetParts[index], true
+	v, ok := params[key]
+	return v, ok
 }
 
 func (h Handler) ParamInt(url string, key string) (int, bool) {
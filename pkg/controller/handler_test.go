@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/version-1/gooo/pkg/http/request"
+	"github.com/version-1/gooo/pkg/http/response"
+)
+
+func TestGroupHandlerMiddlewares(t *testing.T) {
+	output := []string{}
+	record := func(name string) Middleware {
+		return Middleware{
+			Name: name,
+			If:   Always,
+			Do: func(w *response.Response, r *request.Request) bool {
+				output = append(output, name)
+				return true
+			},
+		}
+	}
+
+	var guard BeforeHandlerFunc = func(w *response.Response, r *request.Request) bool {
+		output = append(output, "before")
+		return r.Request.Header.Get("Authorization") != ""
+	}
+
+	admin := GroupHandler{
+		Path:        "/admin",
+		Middlewares: Middlewares{record("admin")},
+	}
+	admin.Add(Handler{
+		Path:          "/users",
+		Method:        http.MethodGet,
+		BeforeHandler: &guard,
+		Handler: func(w *response.Response, r *request.Request) {
+			output = append(output, "handler")
+		},
+	}.With(record("route")))
+
+	api := GroupHandler{Path: "/api/v1"}
+	api.Use(record("api"))
+	api.Add(Get("/ping", func(w *response.Response, r *request.Request) {
+		output = append(output, "ping")
+	}))
+	api.AddGroup(admin)
+
+	router := NewRouter(api.List()...)
+
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		expect []string
+	}{
+		{
+			name:   "group middleware",
+			path:   "/api/v1/ping",
+			expect: []string{"api", "ping"},
+		},
+		{
+			name:   "nested group and route middleware",
+			path:   "/api/v1/admin/users",
+			header: http.Header{"Authorization": []string{"Bearer token"}},
+			expect: []string{"api", "admin", "route", "before", "handler"},
+		},
+		{
+			name:   "before handler stops the chain",
+			path:   "/api/v1/admin/users",
+			expect: []string{"api", "admin", "route", "before"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output = []string{}
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+
+			w := response.New(httptest.NewRecorder(), response.Options{})
+			if !router.Dispatch(w, &request.Request{Request: req}) {
+				t.Fatalf("expected %s to be dispatched", tt.path)
+			}
+
+			if !reflect.DeepEqual(output, tt.expect) {
+				t.Errorf("expect %v, got %v", tt.expect, output)
+			}
+		})
+	}
+}
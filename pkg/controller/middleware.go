@@ -2,14 +2,20 @@ package controller
 
 import (
 	"bytes"
+	gocontext "context"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/version-1/gooo/pkg/context"
+	goooerrors "github.com/version-1/gooo/pkg/errors"
 	"github.com/version-1/gooo/pkg/http/request"
 	"github.com/version-1/gooo/pkg/http/response"
 	"github.com/version-1/gooo/pkg/logger"
+	"github.com/version-1/gooo/pkg/presenter/jsonapi"
 )
 
 type Middlewares []Middleware
@@ -39,25 +45,65 @@ func (m *Middlewares) Prepend(mw ...Middleware) {
 	*m = list
 }
 
+// Then composes the middlewares around h. The first middleware is the
+// outermost layer, so it runs first and returns last.
+func (m Middlewares) Then(h HandlerFunc) HandlerFunc {
+	for i := len(m) - 1; i >= 0; i-- {
+		h = m[i].Apply(h)
+	}
+
+	return h
+}
+
+// Middleware wraps the next handler of the chain. Wrap takes precedence over
+// Do, which is kept for middlewares that only run before the handler and
+// stop the chain by returning false.
 type Middleware struct {
 	Name string
 	If   func(*request.Request) bool
 	Do   func(*response.Response, *request.Request) bool
+	Wrap func(next HandlerFunc) HandlerFunc
 }
 
 func (m Middleware) String() string {
 	return fmt.Sprintf("Middleware %s", m.Name)
 }
 
+func (m Middleware) Apply(next HandlerFunc) HandlerFunc {
+	wrapped := next
+	switch {
+	case m.Wrap != nil:
+		wrapped = m.Wrap(next)
+	case m.Do != nil:
+		wrapped = func(w *response.Response, r *request.Request) {
+			if !m.Do(w, r) {
+				return
+			}
+
+			next(w, r)
+		}
+	}
+
+	return func(w *response.Response, r *request.Request) {
+		if m.If != nil && !m.If(r) {
+			next(w, r)
+			return
+		}
+
+		wrapped(w, r)
+	}
+}
+
 func Always(r *request.Request) bool {
 	return true
 }
 
 func RequestLogger(logger logger.Logger) Middleware {
 	return Middleware{
-		If: Always,
+		Name: "RequestLogger",
+		If:   Always,
 		Do: func(w *response.Response, r *request.Request) bool {
-			logger.Infof("%s %s", r.Request.Method, r.Request.URL.Path)
+			context.ScopedLogger(r.Context(), logger).Infof("%s %s", r.Request.Method, r.Request.URL.Path)
 			return true
 		},
 	}
@@ -65,18 +111,24 @@ func RequestLogger(logger logger.Logger) Middleware {
 
 func ResponseLogger(logger logger.Logger) Middleware {
 	return Middleware{
-		If: Always,
-		Do: func(w *response.Response, r *request.Request) bool {
-			logger.Infof("Status: %d", w.StatusCode())
-			return true
+		Name: "ResponseLogger",
+		If:   Always,
+		Wrap: func(next HandlerFunc) HandlerFunc {
+			return func(w *response.Response, r *request.Request) {
+				start := time.Now()
+				next(w, r)
+				context.ScopedLogger(r.Context(), logger).Infof("Status: %d (%s)", w.StatusCode(), time.Since(start))
+			}
 		},
 	}
 }
 
 func RequestBodyLogger(logger logger.Logger) Middleware {
 	return Middleware{
-		If: Always,
+		Name: "RequestBodyLogger",
+		If:   Always,
 		Do: func(w *response.Response, r *request.Request) bool {
+			logger := context.ScopedLogger(r.Context(), logger)
 			b, err := io.ReadAll(r.Request.Body)
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
@@ -85,7 +137,8 @@ func RequestBodyLogger(logger logger.Logger) Middleware {
 				return false
 			}
 
-			io.Copy(w, io.MultiReader(bytes.NewReader(b), r.Request.Body))
+			r.Request.Body.Close()
+			r.Request.Body = io.NopCloser(bytes.NewReader(b))
 			if len(b) > 0 {
 				logger.Infof("body: %s", b)
 			}
@@ -96,8 +149,10 @@ func RequestBodyLogger(logger logger.Logger) Middleware {
 
 func RequestHeaderLogger(logger logger.Logger) Middleware {
 	return Middleware{
-		If: Always,
+		Name: "RequestHeaderLogger",
+		If:   Always,
 		Do: func(w *response.Response, r *request.Request) bool {
+			logger := context.ScopedLogger(r.Context(), logger)
 			logger.Infof("HTTP Headers: ")
 			for k, v := range r.Request.Header {
 				logger.Infof("%s: %s", k, v)
@@ -107,9 +162,193 @@ func RequestHeaderLogger(logger logger.Logger) Middleware {
 	}
 }
 
+// PanicHook is called with the recovered panic, e.g. to report it to an error
+// tracker. The default response is skipped when a hook writes a response.
+type PanicHook func(w *response.Response, r *request.Request, err error)
+
+// Recover turns a panic of the next handlers into a 500 response rendered by
+// the response adapter. Any panic value is converted into an error with the
+// stack trace of the panic.
+func Recover(logger logger.Logger, hooks ...PanicHook) Middleware {
+	return Middleware{
+		Name: "Recover",
+		If:   Always,
+		Wrap: func(next HandlerFunc) HandlerFunc {
+			return func(w *response.Response, r *request.Request) {
+				defer func() {
+					v := recover()
+					if v == nil {
+						return
+					}
+
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+
+					err := panicError(v)
+					context.ScopedLogger(r.Context(), logger).Errorf("Caught panic: %+v", err)
+					for _, h := range hooks {
+						h(w, r, err)
+					}
+
+					if w.Written() {
+						return
+					}
+
+					b, rerr := w.Adapter().RenderError(err)
+					w.InternalServerError()
+					if rerr != nil {
+						logger.Errorf("failed to render panic response: %s", rerr)
+						return
+					}
+					w.Write(b)
+				}()
+
+				next(w, r)
+			}
+		},
+	}
+}
+
+func panicError(v any) error {
+	if err, ok := v.(*goooerrors.Error); ok {
+		return err
+	}
+
+	if err, ok := v.(error); ok {
+		return goooerrors.Wrap(err)
+	}
+
+	return goooerrors.Errorf("panic: %v", v)
+}
+
+// TxBeginner begins the transaction of a request, e.g.
+//
+//	func(ctx gocontext.Context) (context.Transaction, error) {
+//		return o.Begin(ctx)
+//	}
+type TxBeginner func(ctx gocontext.Context) (context.Transaction, error)
+
+// Transaction runs the next handlers in a transaction stored in the request
+// context, where the generated models and orm.Runner find it. It is committed
+// when the response status is 2xx and rolled back otherwise or when the next
+// handlers panic, in which case the panic is propagated to Recover. Use it
+// per route with Handler.With, or narrow it with If.
+//
+// The response is buffered until the transaction is done, so that a failed
+// commit is reported with 500 instead of the response of the handlers. When
+// Transaction is nested, e.g. globally and per route, the inner response is
+// buffered again in the outer one. Since the buffered writer is no
+// http.Flusher, skip Transaction with If for handlers that stream.
+func Transaction(begin TxBeginner, logger logger.Logger) Middleware {
+	return Middleware{
+		Name: "Transaction",
+		If:   Always,
+		Wrap: func(next HandlerFunc) HandlerFunc {
+			return func(w *response.Response, r *request.Request) {
+				logger := context.ScopedLogger(r.Context(), logger)
+				tx, err := begin(r.Context())
+				if err != nil {
+					logger.Errorf("failed to begin transaction: %s", err)
+					w.InternalServerErrorWith(err)
+					return
+				}
+
+				done := false
+				w.Buffer()
+				defer func() {
+					if done {
+						return
+					}
+
+					w.DiscardBuffer()
+					if err := tx.Rollback(); err != nil {
+						logger.Errorf("failed to roll back transaction: %s", err)
+					}
+				}()
+
+				r.WithContext(context.WithTx(r.Context(), tx))
+				next(w, r)
+
+				done = true
+				if code := w.StatusCode(); code < 200 || code >= 300 {
+					if err := tx.Rollback(); err != nil {
+						logger.Errorf("failed to roll back transaction: %s", err)
+					}
+				} else if err := tx.Commit(); err != nil {
+					logger.Errorf("failed to commit transaction: %s", err)
+					w.DiscardBuffer()
+					w.InternalServerErrorWith(err)
+					return
+				}
+
+				if err := w.FlushBuffer(); err != nil {
+					logger.Errorf("failed to write response: %s", err)
+				}
+			}
+		},
+	}
+}
+
+// RequestID takes the request id from the X-Request-ID header or generates a
+// new one, stores it in the request context and echoes it in the response.
+func RequestID() Middleware {
+	return Middleware{
+		Name: "RequestID",
+		If:   Always,
+		Do: func(w *response.Response, r *request.Request) bool {
+			id := r.Request.Header.Get(context.RequestIDHeader)
+			if !validRequestID(id) {
+				id = uuid.New().String()
+			}
+
+			r.WithContext(context.WithRequestID(r.Context(), id))
+			w.Header().Set(context.RequestIDHeader, id)
+			return true
+		},
+	}
+}
+
+const maxRequestIDLength = 128
+
+// validRequestID only accepts printable ASCII so that a client can not break
+// log lines with the header value.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ContentNegotiation picks the response renderer from the Accept header and
+// responds with 406 when no registered renderer is acceptable.
+func ContentNegotiation() Middleware {
+	return Middleware{
+		Name: "ContentNegotiation",
+		If:   Always,
+		Do: func(w *response.Response, r *request.Request) bool {
+			accept := r.Request.Header.Get("Accept")
+			if w.Negotiate(accept) {
+				return true
+			}
+
+			w.NotAcceptableWith(jsonapi.NewNotAcceptable(fmt.Errorf("no representation is acceptable for %q", accept)))
+			return false
+		},
+	}
+}
+
 func CORS(origin, methods, headers []string) Middleware {
 	return Middleware{
-		If: Always,
+		Name: "CORS",
+		If:   Always,
 		Do: func(w *response.Response, r *request.Request) bool {
 			w.Header().Set("Access-Control-Allow-Origin", strings.Join(origin, ", "))
 			w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
@@ -121,7 +360,8 @@ func CORS(origin, methods, headers []string) Middleware {
 
 func WithContext(callbacks ...func(r *request.Request) *request.Request) Middleware {
 	return Middleware{
-		If: Always,
+		Name: "WithContext",
+		If:   Always,
 		Do: func(w *response.Response, r *request.Request) bool {
 			for _, cb := range callbacks {
 				*r = *cb(r)
@@ -133,25 +373,17 @@ func WithContext(callbacks ...func(r *request.Request) *request.Request) Middlew
 }
 
 func RequestHandler(handlers []Handler) Middleware {
+	router := NewRouter(handlers...)
+
 	return Middleware{
-		If: Always,
-		Do: func(w *response.Response, r *request.Request) bool {
-			match := false
-			for _, handler := range handlers {
-				if handler.Match(r) {
-					if handler.BeforeHandler != nil {
-						(*handler.BeforeHandler)(w, r)
-					}
-					handler.Handler(w, r)
-					match = true
-					break
+		Name: "RequestHandler",
+		If:   Always,
+		Wrap: func(next HandlerFunc) HandlerFunc {
+			return func(w *response.Response, r *request.Request) {
+				if router.Dispatch(w, r) {
+					next(w, r)
 				}
 			}
-			if !match {
-				w.NotFoundWith(fmt.Errorf("Not found endpoint: %s", r.Request.URL.Path))
-			}
-
-			return match
 		},
 	}
 }
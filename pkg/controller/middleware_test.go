@@ -1,12 +1,21 @@
 package controller
 
 import (
+	"bytes"
+	gocontext "context"
+	"database/sql"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/version-1/gooo/pkg/context"
 	"github.com/version-1/gooo/pkg/http/request"
 	"github.com/version-1/gooo/pkg/http/response"
+	"github.com/version-1/gooo/pkg/logger"
 )
 
 func TestMiddleware(t *testing.T) {
@@ -64,3 +73,319 @@ func TestMiddleware(t *testing.T) {
 		fmt.Printf("order of middlewares is incorrect. expect %v, got %v", expect, output)
 	}
 }
+
+func TestMiddlewaresThen(t *testing.T) {
+	output := []string{}
+	record := func(name string) Middleware {
+		return Middleware{
+			Name: name,
+			Wrap: func(next HandlerFunc) HandlerFunc {
+				return func(w *response.Response, r *request.Request) {
+					output = append(output, "before:"+name)
+					next(w, r)
+					output = append(output, "after:"+name)
+				}
+			},
+		}
+	}
+
+	mw := Middlewares{
+		record("outer"),
+		{
+			Name: "skipped",
+			If:   func(*request.Request) bool { return false },
+			Do: func(w *response.Response, r *request.Request) bool {
+				output = append(output, "skipped")
+				return false
+			},
+		},
+		{
+			Name: "legacy",
+			If:   Always,
+			Do: func(w *response.Response, r *request.Request) bool {
+				output = append(output, "legacy")
+				return true
+			},
+		},
+		record("inner"),
+	}
+
+	h := mw.Then(func(w *response.Response, r *request.Request) {
+		output = append(output, "handler")
+	})
+	h(nil, &request.Request{})
+
+	expect := []string{"before:outer", "legacy", "before:inner", "handler", "after:inner", "after:outer"}
+	if !reflect.DeepEqual(output, expect) {
+		t.Errorf("expect %v, got %v", expect, output)
+	}
+}
+
+func TestMiddlewaresThenShortCircuit(t *testing.T) {
+	output := []string{}
+	mw := Middlewares{
+		{
+			Name: "guard",
+			If:   Always,
+			Do: func(w *response.Response, r *request.Request) bool {
+				output = append(output, "guard")
+				return false
+			},
+		},
+	}
+
+	h := mw.Then(func(w *response.Response, r *request.Request) {
+		output = append(output, "handler")
+	})
+	h(nil, &request.Request{})
+
+	expect := []string{"guard"}
+	if !reflect.DeepEqual(output, expect) {
+		t.Errorf("expect %v, got %v", expect, output)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logger.New(logger.Options{Level: logger.LogLevelInfo, Output: buf})
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "reuse the incoming id", header: "abc-123", keep: true},
+		{name: "generate when missing", header: ""},
+		{name: "generate when invalid", header: "bad id\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf.Reset()
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if test.header != "" {
+				req.Header.Set(context.RequestIDHeader, test.header)
+			}
+
+			var got string
+			h := Middlewares{RequestID(), RequestLogger(l)}.Then(func(w *response.Response, r *request.Request) {
+				got = context.RequestID(r.Context())
+			})
+			h(response.New(rec, response.Options{}), &request.Request{Request: req})
+
+			if test.keep && got != test.header {
+				t.Errorf("expect %q, got %q", test.header, got)
+			}
+
+			if !test.keep && (got == "" || got == test.header) {
+				t.Errorf("expect a generated id, got %q", got)
+			}
+
+			if h := rec.Header().Get(context.RequestIDHeader); h != got {
+				t.Errorf("expect response header %q, got %q", got, h)
+			}
+
+			if !strings.Contains(buf.String(), "request_id="+got) {
+				t.Errorf("expect log to contain the request id %q, got %q", got, buf.String())
+			}
+		})
+	}
+}
+
+func TestRequestBodyLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := logger.New(logger.Options{Level: logger.LogLevelInfo, Output: out})
+
+	body := ""
+	h := Middlewares{RequestBodyLogger(l)}.Then(func(w *response.Response, r *request.Request) {
+		b, err := io.ReadAll(r.Request.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body = string(b)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"gooo"}`))
+	h(response.New(rec, response.Options{}), &request.Request{Request: req})
+
+	if body != `{"name":"gooo"}` {
+		t.Errorf("expect the body to be readable by the handler, got %q", body)
+	}
+
+	if rec.Body.Len() > 0 {
+		t.Errorf("expect the body not to be written to the response, got %q", rec.Body)
+	}
+
+	if !strings.Contains(out.String(), `body: {"name":"gooo"}`) {
+		t.Errorf("expect the body to be logged, got %q", out)
+	}
+}
+
+type fakeTx struct {
+	context.Transaction
+	committed  bool
+	rolledBack bool
+	commitErr  error
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+func TestTransaction(t *testing.T) {
+	l := logger.New(logger.Options{Level: logger.LogLevelInfo, Output: &bytes.Buffer{}})
+
+	tests := []struct {
+		name     string
+		handler  HandlerFunc
+		panics   bool
+		commit   bool
+		rollback bool
+	}{
+		{
+			name:    "commit on success",
+			handler: func(w *response.Response, r *request.Request) { w.Write([]byte("ok")) },
+			commit:  true,
+		},
+		{
+			name:     "roll back on error response",
+			handler:  func(w *response.Response, r *request.Request) { w.BadRequest() },
+			rollback: true,
+		},
+		{
+			name:     "roll back on panic",
+			handler:  func(w *response.Response, r *request.Request) { panic("boom") },
+			panics:   true,
+			rollback: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := &fakeTx{}
+			begin := func(ctx gocontext.Context) (context.Transaction, error) {
+				return tx, nil
+			}
+
+			h := Middlewares{Transaction(begin, l)}.Then(func(w *response.Response, r *request.Request) {
+				if got, ok := context.Tx(r.Context()); !ok || got != tx {
+					t.Errorf("expect the transaction in the context, got %v", got)
+				}
+
+				test.handler(w, r)
+			})
+
+			rec := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if v := recover(); (v != nil) != test.panics {
+						t.Errorf("expect panic %t, got %v", test.panics, v)
+					}
+				}()
+
+				req := httptest.NewRequest(http.MethodPost, "/users", nil)
+				h(response.New(rec, response.Options{}), &request.Request{Request: req})
+			}()
+
+			if tx.committed != test.commit || tx.rolledBack != test.rollback {
+				t.Errorf("expect commit %t and rollback %t, got %t and %t", test.commit, test.rollback, tx.committed, tx.rolledBack)
+			}
+
+			if test.commit && rec.Body.String() != "ok" {
+				t.Errorf("expect the response to be written after commit, got %q", rec.Body)
+			}
+		})
+	}
+
+	t.Run("commit error", func(t *testing.T) {
+		tx := &fakeTx{commitErr: sql.ErrConnDone}
+		begin := func(ctx gocontext.Context) (context.Transaction, error) {
+			return tx, nil
+		}
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/users", nil)
+		h := Middlewares{Transaction(begin, l)}.Then(func(w *response.Response, r *request.Request) {
+			w.Header().Set("Location", "/users/1")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created"))
+		})
+		h(response.New(rec, response.Options{}), &request.Request{Request: req})
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expect status %d, got %d", http.StatusInternalServerError, rec.Code)
+		}
+
+		if strings.Contains(rec.Body.String(), "created") || rec.Header().Get("Location") != "" {
+			t.Errorf("expect the response of the handler to be discarded, got %v %s", rec.Header(), rec.Body)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			outer *fakeTx
+			inner *fakeTx
+		}{
+			{name: "outer commit error", outer: &fakeTx{commitErr: sql.ErrConnDone}, inner: &fakeTx{}},
+			{name: "inner commit error", outer: &fakeTx{}, inner: &fakeTx{commitErr: sql.ErrConnDone}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				txs := []*fakeTx{test.outer, test.inner}
+				begin := func(ctx gocontext.Context) (context.Transaction, error) {
+					tx := txs[0]
+					txs = txs[1:]
+					return tx, nil
+				}
+
+				rec := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/users", nil)
+				h := Middlewares{Transaction(begin, l), Transaction(begin, l)}.Then(func(w *response.Response, r *request.Request) {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte("created"))
+				})
+				h(response.New(rec, response.Options{}), &request.Request{Request: req})
+
+				if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "created") {
+					t.Errorf("expect status %d without the response of the handler, got %d %s", http.StatusInternalServerError, rec.Code, rec.Body)
+				}
+
+				if test.inner.commitErr != nil && !test.outer.rolledBack {
+					t.Error("expect the outer transaction to be rolled back")
+				}
+			})
+		}
+	})
+
+	t.Run("begin error", func(t *testing.T) {
+		called := false
+		begin := func(ctx gocontext.Context) (context.Transaction, error) {
+			return nil, sql.ErrConnDone
+		}
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/users", nil)
+		h := Middlewares{Transaction(begin, l)}.Then(func(w *response.Response, r *request.Request) {
+			called = true
+		})
+		h(response.New(rec, response.Options{}), &request.Request{Request: req})
+
+		if called {
+			t.Error("expect the handler not to be called")
+		}
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expect status %d, got %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+}
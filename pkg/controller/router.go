@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/version-1/gooo/pkg/http/request"
+	"github.com/version-1/gooo/pkg/http/response"
+)
+
+var _ request.ParamParser = &Route{}
+
+const (
+	paramPrefix    = ':'
+	wildcardPrefix = '*'
+)
+
+// Router dispatches requests to handlers through a trie keyed by path segment.
+// Static segments take priority over `:param` segments, which take priority
+// over `*wildcard` catch-alls.
+type Router struct {
+	root *node
+}
+
+func NewRouter(handlers ...Handler) *Router {
+	r := &Router{root: &node{}}
+	r.Add(handlers...)
+
+	return r
+}
+
+func (r *Router) Add(handlers ...Handler) {
+	for _, h := range handlers {
+		r.root.insert(splitPath(h.Path), h)
+		compile(h.Path)
+	}
+}
+
+// Route is a matched handler along with the params captured from the path.
+type Route struct {
+	Handler Handler
+	Params  map[string]string
+}
+
+func (r Route) Param(_ string, key string) (string, bool) {
+	v, ok := r.Params[key]
+	return v, ok
+}
+
+func (r Route) ParamInt(url string, key string) (int, bool) {
+	v, ok := r.Param(url, key)
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// Lookup returns the route for the method and path. When the path matches but
+// no handler is registered for the method, route is nil and allowed lists the
+// methods registered for the path.
+func (r *Router) Lookup(method, path string) (route *Route, allowed []string) {
+	matches := []match{}
+	r.root.lookup(splitPath(path), map[string]string{}, &matches)
+
+	methods := map[string]bool{}
+	for _, m := range matches {
+		if h, ok := m.node.handlers[method]; ok {
+			return &Route{Handler: h, Params: m.params}, nil
+		}
+
+		for k := range m.node.handlers {
+			methods[k] = true
+		}
+	}
+
+	for k := range methods {
+		allowed = append(allowed, k)
+	}
+	sort.Strings(allowed)
+
+	return nil, allowed
+}
+
+func (r *Router) Dispatch(w *response.Response, req *request.Request) bool {
+	route, allowed := r.Lookup(req.Request.Method, req.Request.URL.Path)
+	if route == nil {
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			w.MethodNotAllowedWith(fmt.Errorf("Method not allowed: %s %s", req.Request.Method, req.Request.URL.Path))
+			return false
+		}
+
+		w.NotFoundWith(fmt.Errorf("Not found endpoint: %s", req.Request.URL.Path))
+		return false
+	}
+
+	req.Handler = route
+	route.Handler.Chain()(w, req)
+
+	return true
+}
+
+// Walk calls cb for every registered handler in priority order.
+func (r *Router) Walk(cb func(h Handler)) {
+	r.root.walk(cb)
+}
+
+type match struct {
+	node   *node
+	params map[string]string
+}
+
+type node struct {
+	segment  string
+	static   []*node
+	params   []*node
+	wildcard *node
+	handlers map[string]Handler
+}
+
+func (n *node) name() string {
+	return n.segment[1:]
+}
+
+func (n *node) insert(segments []string, h Handler) {
+	if len(segments) == 0 {
+		if n.handlers == nil {
+			n.handlers = map[string]Handler{}
+		}
+
+		if _, ok := n.handlers[h.Method]; ok {
+			panic(fmt.Sprintf("controller: duplicated route %s", h))
+		}
+
+		n.handlers[h.Method] = h
+		return
+	}
+
+	seg := segments[0]
+	switch seg[0] {
+	case wildcardPrefix:
+		if len(segments) > 1 {
+			panic(fmt.Sprintf("controller: wildcard must be the last segment: %s", h))
+		}
+
+		if n.wildcard == nil {
+			n.wildcard = &node{segment: seg}
+		}
+
+		if n.wildcard.segment != seg {
+			panic(fmt.Sprintf("controller: conflicting wildcard %s and %s: %s", n.wildcard.segment, seg, h))
+		}
+
+		n.wildcard.insert(nil, h)
+	case paramPrefix:
+		child := find(n.params, seg)
+		if child == nil {
+			child = &node{segment: seg}
+			n.params = append(n.params, child)
+		}
+
+		child.insert(segments[1:], h)
+	default:
+		child := find(n.static, seg)
+		if child == nil {
+			child = &node{segment: seg}
+			n.static = append(n.static, child)
+			sort.Slice(n.static, func(i, j int) bool {
+				return n.static[i].segment < n.static[j].segment
+			})
+		}
+
+		child.insert(segments[1:], h)
+	}
+}
+
+// lookup collects every node matching the segments, ordered by priority.
+func (n *node) lookup(segments []string, params map[string]string, matches *[]match) {
+	if len(segments) == 0 {
+		if len(n.handlers) > 0 {
+			*matches = append(*matches, match{node: n, params: copyParams(params)})
+		}
+	} else {
+		seg := segments[0]
+		i := sort.Search(len(n.static), func(i int) bool {
+			return n.static[i].segment >= seg
+		})
+		if i < len(n.static) && n.static[i].segment == seg {
+			n.static[i].lookup(segments[1:], params, matches)
+		}
+
+		for _, child := range n.params {
+			params[child.name()] = seg
+			child.lookup(segments[1:], params, matches)
+			delete(params, child.name())
+		}
+	}
+
+	if n.wildcard != nil && len(n.wildcard.handlers) > 0 {
+		p := copyParams(params)
+		p[n.wildcard.name()] = strings.Join(segments, "/")
+		*matches = append(*matches, match{node: n.wildcard, params: p})
+	}
+}
+
+func (n *node) walk(cb func(h Handler)) {
+	methods := []string{}
+	for k := range n.handlers {
+		methods = append(methods, k)
+	}
+	sort.Strings(methods)
+
+	for _, m := range methods {
+		cb(n.handlers[m])
+	}
+
+	for _, child := range n.static {
+		child.walk(cb)
+	}
+
+	for _, child := range n.params {
+		child.walk(cb)
+	}
+
+	if n.wildcard != nil {
+		n.wildcard.walk(cb)
+	}
+}
+
+func find(list []*node, segment string) *node {
+	for _, n := range list {
+		if n.segment == segment {
+			return n
+		}
+	}
+
+	return nil
+}
+
+func copyParams(params map[string]string) map[string]string {
+	m := make(map[string]string, len(params))
+	for k, v := range params {
+		m[k] = v
+	}
+
+	return m
+}
+
+func splitPath(path string) []string {
+	segments := []string{}
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	return segments
+}
+
+// patterns caches the trie of each route pattern, so that Handler.Match and
+// Handler.Param don't build it on every request.
+var patterns sync.Map
+
+// compile returns the trie matching the pattern alone.
+func compile(pattern string) *node {
+	if n, ok := patterns.Load(pattern); ok {
+		return n.(*node)
+	}
+
+	n := &node{}
+	n.insert(splitPath(pattern), Handler{Path: pattern, Method: http.MethodGet})
+	v, _ := patterns.LoadOrStore(pattern, n)
+
+	return v.(*node)
+}
+
+// matchPath matches a single route pattern against the path.
+func matchPath(pattern, path string) (map[string]string, bool) {
+	n := compile(pattern)
+
+	matches := []match{}
+	n.lookup(splitPath(path), map[string]string{}, &matches)
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	return matches[0].params, true
+}
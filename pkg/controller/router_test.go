@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/version-1/gooo/pkg/http/request"
+	"github.com/version-1/gooo/pkg/http/response"
+)
+
+func noop(w *response.Response, r *request.Request) {}
+
+func TestRouterLookup(t *testing.T) {
+	users := GroupHandler{
+		Path: "/users",
+		Handlers: []Handler{
+			Get("/", noop),
+			Post("/", noop),
+			Get("/new", noop),
+			Get("/:id", noop),
+			Patch("/:id", noop),
+			Get("/:id/posts/:post_id", noop),
+		},
+	}
+
+	router := NewRouter(users.List()...)
+	router.Add(Get("/files/*filepath", noop))
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		pattern string
+		params  map[string]string
+		allowed []string
+	}{
+		{
+			name:    "static",
+			method:  http.MethodGet,
+			path:    "/users",
+			pattern: "/users",
+			params:  map[string]string{},
+		},
+		{
+			name:    "trailing slash",
+			method:  http.MethodPost,
+			path:    "/users/",
+			pattern: "/users",
+			params:  map[string]string{},
+		},
+		{
+			name:    "static over param",
+			method:  http.MethodGet,
+			path:    "/users/new",
+			pattern: "/users/new",
+			params:  map[string]string{},
+		},
+		{
+			name:    "param",
+			method:  http.MethodGet,
+			path:    "/users/10",
+			pattern: "/users/:id",
+			params:  map[string]string{"id": "10"},
+		},
+		{
+			name:    "falls back to param when static lacks method",
+			method:  http.MethodPatch,
+			path:    "/users/new",
+			pattern: "/users/:id",
+			params:  map[string]string{"id": "new"},
+		},
+		{
+			name:    "nested params",
+			method:  http.MethodGet,
+			path:    "/users/10/posts/20",
+			pattern: "/users/:id/posts/:post_id",
+			params:  map[string]string{"id": "10", "post_id": "20"},
+		},
+		{
+			name:    "wildcard",
+			method:  http.MethodGet,
+			path:    "/files/css/app.css",
+			pattern: "/files/*filepath",
+			params:  map[string]string{"filepath": "css/app.css"},
+		},
+		{
+			name:    "method not allowed",
+			method:  http.MethodDelete,
+			path:    "/users/10",
+			allowed: []string{http.MethodGet, http.MethodPatch},
+		},
+		{
+			name:   "not found",
+			method: http.MethodGet,
+			path:   "/posts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, allowed := router.Lookup(tt.method, tt.path)
+			if tt.pattern == "" {
+				if route != nil {
+					t.Fatalf("expected no route, got %s", route.Handler)
+				}
+
+				if !reflect.DeepEqual(allowed, tt.allowed) {
+					t.Errorf("expected allowed %v, got %v", tt.allowed, allowed)
+				}
+				return
+			}
+
+			if route == nil {
+				t.Fatalf("expected route %s, got nil", tt.pattern)
+			}
+
+			if route.Handler.Path != tt.pattern {
+				t.Errorf("expected pattern %s, got %s", tt.pattern, route.Handler.Path)
+			}
+
+			if !reflect.DeepEqual(route.Params, tt.params) {
+				t.Errorf("expected params %v, got %v", tt.params, route.Params)
+			}
+		})
+	}
+}
+
+func TestRouterDispatch(t *testing.T) {
+	var id int
+	router := NewRouter(
+		Get("/users/:id", func(w *response.Response, r *request.Request) {
+			id, _ = r.ParamInt("id")
+		}),
+		Patch("/users/:id", noop),
+	)
+
+	w := httptest.NewRecorder()
+	req := &request.Request{Request: httptest.NewRequest(http.MethodGet, "/users/42", nil)}
+	if !router.Dispatch(response.New(w, response.Options{}), req) {
+		t.Fatal("expected request to be dispatched")
+	}
+
+	if id != 42 {
+		t.Errorf("expected param id to be 42, got %d", id)
+	}
+
+	w = httptest.NewRecorder()
+	req = &request.Request{Request: httptest.NewRequest(http.MethodPost, "/users/42", nil)}
+	if router.Dispatch(response.New(w, response.Options{}), req) {
+		t.Fatal("expected request not to be dispatched")
+	}
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	if allow := w.Header().Get("Allow"); allow != "GET, PATCH" {
+		t.Errorf("expected Allow header %q, got %q", "GET, PATCH", allow)
+	}
+}
+
+func TestHandlerMatch(t *testing.T) {
+	h := Get("/users/:id", noop)
+	r := &request.Request{Request: httptest.NewRequest(http.MethodGet, "/users/1", nil)}
+	if !h.Match(r) {
+		t.Error("expected handler to match /users/1")
+	}
+
+	if v, ok := h.Param("/users/1", "id"); !ok || v != "1" {
+		t.Errorf("expected param id to be 1, got %s", v)
+	}
+
+	r = &request.Request{Request: httptest.NewRequest(http.MethodGet, "/users/1/posts", nil)}
+	if h.Match(r) {
+		t.Error("expected handler not to match /users/1/posts")
+	}
+}
+
+func TestMatchPathCachesPattern(t *testing.T) {
+	NewRouter(Get("/cached/:id", noop))
+	n := compile("/cached/:id")
+
+	if params, ok := matchPath("/cached/:id", "/cached/1"); !ok || params["id"] != "1" {
+		t.Errorf("expected id 1, got %v", params)
+	}
+
+	if compile("/cached/:id") != n {
+		t.Error("expected the pattern to be compiled once")
+	}
+}
@@ -1,18 +1,18 @@
 package logging
 
 import (
+	gocontext "context"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
-)
 
-type driver interface {
-	Infof(string, ...any)
-}
+	"github.com/version-1/gooo/pkg/context"
+	"github.com/version-1/gooo/pkg/logger"
+)
 
 type QueryLogger struct {
-	driver driver
+	driver logger.Printer
 }
 
 func (l QueryLogger) Info(query string, args ...any) {
@@ -20,7 +20,13 @@ func (l QueryLogger) Info(query string, args ...any) {
 	l.driver.Infof("Query: %s\n Args: %s\n", s, resolveArgs(args))
 }
 
-func NewQueryLogger(driver driver) *QueryLogger {
+// InfoContext is the same as Info but tags the entry with the request id of
+// ctx.
+func (l QueryLogger) InfoContext(ctx gocontext.Context, query string, args ...any) {
+	QueryLogger{driver: logger.WithRequestID(l.driver, context.RequestID(ctx))}.Info(query, args...)
+}
+
+func NewQueryLogger(driver logger.Printer) *QueryLogger {
 	return &QueryLogger{driver: driver}
 }
 
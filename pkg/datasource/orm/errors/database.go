@@ -0,0 +1,159 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var _ DatabaseError = UniqueViolationError{}
+var _ DatabaseError = ForeignKeyViolationError{}
+var _ DatabaseError = NotNullViolationError{}
+var _ DatabaseError = CheckViolationError{}
+
+// DatabaseError is a constraint violation reported by the database. It
+// renders as a JSON:API error with its own status, code and title, and
+// unwraps to the *pq.Error.
+type DatabaseError interface {
+	error
+	Code() string
+	Title() string
+	HTTPStatus() int
+	Unwrap() error
+}
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	notNullViolation    = "23502"
+	checkViolation      = "23514"
+)
+
+// Translate turns a constraint violation of Postgres into a DatabaseError.
+// Other errors, including ones already translated, are returned as they are.
+func Translate(err error) error {
+	var pqErr *pq.Error
+	if err == nil || !errors.As(err, &pqErr) {
+		return err
+	}
+
+	var translated DatabaseError
+	if errors.As(err, &translated) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case uniqueViolation:
+		return UniqueViolationError{
+			Table:      pqErr.Table,
+			Constraint: pqErr.Constraint,
+			Columns:    keyColumns(pqErr.Detail),
+			err:        pqErr,
+		}
+	case foreignKeyViolation:
+		return ForeignKeyViolationError{
+			Table:      pqErr.Table,
+			Constraint: pqErr.Constraint,
+			Columns:    keyColumns(pqErr.Detail),
+			err:        pqErr,
+		}
+	case notNullViolation:
+		return NotNullViolationError{Table: pqErr.Table, Column: pqErr.Column, err: pqErr}
+	case checkViolation:
+		return CheckViolationError{Table: pqErr.Table, Constraint: pqErr.Constraint, err: pqErr}
+	default:
+		return err
+	}
+}
+
+var keyPattern = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+// keyColumns reads the columns from a detail such as
+// "Key (email)=(a@example.com) already exists.".
+func keyColumns(detail string) []string {
+	m := keyPattern.FindStringSubmatch(detail)
+	if m == nil {
+		return []string{}
+	}
+
+	columns := strings.Split(m[1], ",")
+	for i, c := range columns {
+		columns[i] = strings.TrimSpace(c)
+	}
+
+	return columns
+}
+
+// UniqueViolationError is returned when a row conflicts with an existing one
+// on a unique constraint. It renders as 409 Conflict.
+type UniqueViolationError struct {
+	Table      string
+	Constraint string
+	Columns    []string
+	err        *pq.Error
+}
+
+func (e UniqueViolationError) Error() string {
+	return fmt.Sprintf("unique violation on %s (%s)", e.Constraint, strings.Join(e.Columns, ", "))
+}
+
+func (e UniqueViolationError) Unwrap() error   { return e.err }
+func (e UniqueViolationError) Code() string    { return "unique_violation" }
+func (e UniqueViolationError) Title() string   { return "Conflict" }
+func (e UniqueViolationError) HTTPStatus() int { return http.StatusConflict }
+
+// ForeignKeyViolationError is returned when a row references a missing row,
+// or a referenced row is deleted. It renders as 422 Unprocessable Entity.
+type ForeignKeyViolationError struct {
+	Table      string
+	Constraint string
+	Columns    []string
+	err        *pq.Error
+}
+
+func (e ForeignKeyViolationError) Error() string {
+	return fmt.Sprintf("foreign key violation on %s (%s)", e.Constraint, strings.Join(e.Columns, ", "))
+}
+
+func (e ForeignKeyViolationError) Unwrap() error   { return e.err }
+func (e ForeignKeyViolationError) Code() string    { return "foreign_key_violation" }
+func (e ForeignKeyViolationError) Title() string   { return "Unprocessable Entity" }
+func (e ForeignKeyViolationError) HTTPStatus() int { return http.StatusUnprocessableEntity }
+
+// NotNullViolationError is returned when a NOT NULL column is null. It renders
+// as 422 Unprocessable Entity.
+type NotNullViolationError struct {
+	Table  string
+	Column string
+	err    *pq.Error
+}
+
+func (e NotNullViolationError) Error() string {
+	return fmt.Sprintf("not null violation on %s.%s", e.Table, e.Column)
+}
+
+func (e NotNullViolationError) Unwrap() error   { return e.err }
+func (e NotNullViolationError) Code() string    { return "not_null_violation" }
+func (e NotNullViolationError) Title() string   { return "Unprocessable Entity" }
+func (e NotNullViolationError) HTTPStatus() int { return http.StatusUnprocessableEntity }
+
+// CheckViolationError is returned when a row fails a check constraint. It
+// renders as 422 Unprocessable Entity.
+type CheckViolationError struct {
+	Table      string
+	Constraint string
+	err        *pq.Error
+}
+
+func (e CheckViolationError) Error() string {
+	return fmt.Sprintf("check violation on %s", e.Constraint)
+}
+
+func (e CheckViolationError) Unwrap() error   { return e.err }
+func (e CheckViolationError) Code() string    { return "check_violation" }
+func (e CheckViolationError) Title() string   { return "Unprocessable Entity" }
+func (e CheckViolationError) HTTPStatus() int { return http.StatusUnprocessableEntity }
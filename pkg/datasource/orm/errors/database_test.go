@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+	"github.com/version-1/gooo/pkg/presenter/jsonapi"
+)
+
+var _ jsonapi.CodeGetter = DatabaseError(nil)
+var _ jsonapi.TitleGetter = DatabaseError(nil)
+var _ jsonapi.StatusGetter = DatabaseError(nil)
+
+func TestTranslate(t *testing.T) {
+	unique := &pq.Error{
+		Code:       "23505",
+		Table:      "users",
+		Constraint: "users_email_key",
+		Detail:     "Key (email)=(a@example.com) already exists.",
+	}
+	foreignKey := &pq.Error{
+		Code:       "23503",
+		Table:      "posts",
+		Constraint: "posts_user_id_fkey",
+		Detail:     `Key (user_id, tenant_id)=(1, 2) is not present in table "users".`,
+	}
+	notNull := &pq.Error{Code: "23502", Table: "users", Column: "email"}
+	check := &pq.Error{Code: "23514", Table: "users", Constraint: "users_age_check"}
+	other := &pq.Error{Code: "40001"}
+
+	tests := []struct {
+		name   string
+		err    error
+		expect error
+		status int
+	}{
+		{
+			name:   "unique violation",
+			err:    unique,
+			expect: UniqueViolationError{Table: "users", Constraint: "users_email_key", Columns: []string{"email"}, err: unique},
+			status: http.StatusConflict,
+		},
+		{
+			name:   "foreign key violation",
+			err:    foreignKey,
+			expect: ForeignKeyViolationError{Table: "posts", Constraint: "posts_user_id_fkey", Columns: []string{"user_id", "tenant_id"}, err: foreignKey},
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "not null violation",
+			err:    notNull,
+			expect: NotNullViolationError{Table: "users", Column: "email", err: notNull},
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "check violation",
+			err:    check,
+			expect: CheckViolationError{Table: "users", Constraint: "users_age_check", err: check},
+			status: http.StatusUnprocessableEntity,
+		},
+		{name: "other code", err: other, expect: other},
+		{name: "nil", err: nil, expect: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Translate(test.err)
+			if !reflect.DeepEqual(got, test.expect) {
+				t.Fatalf("expect %#v, got %#v", test.expect, got)
+			}
+
+			if test.status == 0 {
+				return
+			}
+
+			if !errors.Is(got, test.err) {
+				t.Errorf("expect %v to unwrap to the pq error", got)
+			}
+
+			if again := Translate(got); !reflect.DeepEqual(again, got) {
+				t.Errorf("expect a translated error to be kept, got %#v", again)
+			}
+
+			e := jsonapi.NewErrorResponse(goooerrors.Wrap(got)).ToJSONAPIError()
+			if e.Status != test.status || e.Code != got.(DatabaseError).Code() {
+				t.Errorf("expect status %d and code %s, got %d and %s", test.status, got.(DatabaseError).Code(), e.Status, e.Code)
+			}
+		})
+	}
+}
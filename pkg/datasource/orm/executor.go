@@ -3,6 +3,11 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
 )
 
 var _ Tx = &Executor{}
@@ -37,22 +42,60 @@ func (e *Executor) queryRunner() QueryRunner {
 	return e.Orm
 }
 
+// QueryRowContext runs a query returning at most one row in the transaction.
+// The error of Scan is not translated, as *sql.Row can't be wrapped. Pass it
+// to ormerrors.Translate, or use the query builders, whose Row translates it.
 func (e *Executor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	e.Orm.LogQuery(query, args)
+	e.Orm.LogQuery(ctx, query, args)
 
 	return e.queryRunner().QueryRowContext(ctx, query, args...)
 }
 
 func (e *Executor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	e.Orm.LogQuery(query, args)
+	e.Orm.LogQuery(ctx, query, args)
 
-	return e.queryRunner().ExecContext(ctx, query, args...)
+	res, err := e.queryRunner().ExecContext(ctx, query, args...)
+	return res, ormerrors.Translate(err)
 }
 
 func (e *Executor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	e.Orm.LogQuery(query, args)
+	e.Orm.LogQuery(ctx, query, args)
+
+	rows, err := e.queryRunner().QueryContext(ctx, query, args...)
+	return rows, ormerrors.Translate(err)
+}
+
+// Transaction runs fn in a transaction. Within a transaction it runs fn in a
+// nested one with a savepoint, which is rolled back alone when fn fails, so
+// functions opening their own transaction compose. The options apply only
+// outside of a transaction, see Orm.Transaction.
+func (e *Executor) Transaction(ctx context.Context, fn func(*Executor) error, opts ...TxOptions) error {
+	if e.tx == nil {
+		return e.Orm.Transaction(ctx, fn, opts...)
+	}
+
+	savepoint := "sp_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := e.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			e.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(p)
+		}
+	}()
+
+	if err := fn(NewExecutor(e.Orm, e.tx)); err != nil {
+		if _, rerr := e.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+
+		return err
+	}
 
-	return e.queryRunner().QueryContext(ctx, query, args...)
+	_, err := e.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+	return err
 }
 
 func (e *Executor) Commit() error {
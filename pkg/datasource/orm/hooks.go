@@ -0,0 +1,68 @@
+package orm
+
+import "context"
+
+// BeforeCreator and the other hooks are optional methods of a schema called by
+// the generated persistence methods with the same QueryRunner, so they run
+// inside the transaction of the caller. An error returned by a hook aborts the
+// operation, and an error of an After hook rolls the write back, see Atomic.
+//
+//	func (u *User) BeforeCreate(ctx context.Context, qr orm.QueryRunner) error {
+//		u.Email = strings.ToLower(u.Email)
+//		return nil
+//	}
+type BeforeCreator interface {
+	BeforeCreate(ctx context.Context, qr QueryRunner) error
+}
+
+type AfterCreator interface {
+	AfterCreate(ctx context.Context, qr QueryRunner) error
+}
+
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context, qr QueryRunner) error
+}
+
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context, qr QueryRunner) error
+}
+
+type BeforeDestroyer interface {
+	BeforeDestroy(ctx context.Context, qr QueryRunner) error
+}
+
+type AfterDestroyer interface {
+	AfterDestroy(ctx context.Context, qr QueryRunner) error
+}
+
+// AfterFinder is called for each record read by Find, Reload and scopes.
+type AfterFinder interface {
+	AfterFind(ctx context.Context, qr QueryRunner) error
+}
+
+// Transactor is a QueryRunner running functions in transactions, as Orm and
+// Executor do.
+type Transactor interface {
+	QueryRunner
+	Transaction(ctx context.Context, fn func(*Executor) error, opts ...TxOptions) error
+}
+
+// Atomic runs fn in a transaction, or in a savepoint within one, when qr is a
+// Transactor. A nil qr is resolved with Runner, so that fn runs in a savepoint
+// within the transaction of ctx. The generated persistence methods write and call the After hook
+// in fn, so that an error of the hook rolls the write back. Other runners run
+// fn as is, which is atomic only when qr is a transaction itself.
+func Atomic(ctx context.Context, qr QueryRunner, fn func(qr QueryRunner) error) error {
+	qr, err := Runner(ctx, qr)
+	if err != nil {
+		return err
+	}
+
+	if t, ok := qr.(Transactor); ok {
+		return t.Transaction(ctx, func(ex *Executor) error {
+			return fn(ex)
+		})
+	}
+
+	return fn(qr)
+}
@@ -3,12 +3,17 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/jmoiron/sqlx"
+	gooocontext "github.com/version-1/gooo/pkg/context"
 	"github.com/version-1/gooo/pkg/datasource/logging"
+	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
+	"github.com/version-1/gooo/pkg/logger"
 )
 
 var _ Logger = &logging.MockLogger{}
+var _ Logger = logger.Logger(nil)
 
 type QueryRunner interface {
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
@@ -46,16 +51,81 @@ func New(db *sqlx.DB, logger Logger, options Options) *Orm {
 	return o
 }
 
-func (o *Orm) Transaction(ctx context.Context, fn func(*Executor) error) error {
+// ErrNoQueryRunner is returned by Runner when qr is nil and ctx carries no
+// transaction.
+var ErrNoQueryRunner = errors.New("orm: query runner is nil and the context has no transaction")
+
+// Runner returns qr, or the transaction of ctx when qr is nil, so that the
+// generated models join the transaction opened for the request. It returns
+// ErrNoQueryRunner when neither is set.
+func Runner(ctx context.Context, qr QueryRunner) (QueryRunner, error) {
+	if qr != nil {
+		return qr, nil
+	}
+
+	if tx, ok := gooocontext.Tx(ctx); ok {
+		return tx, nil
+	}
+
+	return nil, ErrNoQueryRunner
+}
+
+// Begin begins a transaction, which the caller commits or rolls back through
+// the returned Executor.
+func (o *Orm) Begin(ctx context.Context) (*Executor, error) {
 	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewExecutor(o, tx), nil
+}
+
+// Transaction runs fn in a new transaction, which is committed when fn
+// succeeds, even when ctx carries one. Calling Transaction of the Executor
+// passed to fn nests a transaction with a savepoint; to nest into the
+// transaction of ctx, pass a nil runner to Runner or Atomic. The options are
+// ignored for nested transactions, which are retried as part of the outermost
+// one.
+func (o *Orm) Transaction(ctx context.Context, fn func(*Executor) error, opts ...TxOptions) error {
+	opt := TxOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := o.transaction(ctx, fn, opt)
+		if err == nil || attempt >= opt.MaxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		o.logger.Warnf("retrying transaction (%d/%d): %s", attempt+1, opt.MaxRetries, err)
+		if err := opt.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// transaction runs fn in a transaction once. The transaction is rolled back
+// when fn fails or panics, and an error rolling back is joined to the error
+// of fn.
+func (o *Orm) transaction(ctx context.Context, fn func(*Executor) error, opt TxOptions) error {
+	tx, err := o.db.BeginTx(ctx, opt.sqlOptions())
 	if err != nil {
 		return err
 	}
 
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
 	ex := NewExecutor(o, tx)
 	if err = fn(ex); err != nil {
-		if err := tx.Rollback(); err != nil {
-			return err
+		if rerr := tx.Rollback(); rerr != nil {
+			return errors.Join(err, rerr)
 		}
 
 		return err
@@ -64,30 +134,35 @@ func (o *Orm) Transaction(ctx context.Context, fn func(*Executor) error) error {
 	return tx.Commit()
 }
 
+// QueryRowContext runs a query returning at most one row.
+// The error of Scan is not translated, as *sql.Row can't be wrapped. Pass it
+// to ormerrors.Translate, or use the query builders, whose Row translates it.
 func (o Orm) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	o.LogQuery(query, args)
+	o.LogQuery(ctx, query, args)
 
 	return o.db.QueryRowContext(ctx, query, args...)
 }
 
 func (o Orm) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	o.LogQuery(query, args)
+	o.LogQuery(ctx, query, args)
 
-	return o.db.ExecContext(ctx, query, args...)
+	res, err := o.db.ExecContext(ctx, query, args...)
+	return res, ormerrors.Translate(err)
 }
 
 func (o Orm) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	o.LogQuery(query, args)
+	o.LogQuery(ctx, query, args)
 
-	return o.db.QueryContext(ctx, query, args...)
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	return rows, ormerrors.Translate(err)
 }
 
-func (o Orm) LogQuery(query string, args []any) {
+func (o Orm) LogQuery(ctx context.Context, query string, args []any) {
 	if !o.options.QueryLog {
 		return
 	}
 
-	o.ql.Info(query, args...)
+	o.ql.InfoContext(ctx, query, args...)
 }
 
 type Scanner interface {
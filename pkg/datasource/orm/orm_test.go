@@ -2,13 +2,16 @@ package orm
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	gooocontext "github.com/version-1/gooo/pkg/context"
 	"github.com/version-1/gooo/pkg/datasource/logging"
 )
 
@@ -73,3 +76,194 @@ func TestTransactionRollback(t *testing.T) {
 		t.Fatalf("expected 0, but got %d", count)
 	}
 }
+
+func TestNestedTransaction(t *testing.T) {
+	db, err := sqlx.Connect("postgres", os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	o := New(db, &logging.MockLogger{}, Options{QueryLog: true})
+	ctx := context.Background()
+
+	if _, err := o.ExecContext(ctx, "DELETE FROM test_transaction;"); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := func(ctx context.Context, e *Executor) error {
+		_, err := e.ExecContext(ctx, "INSERT INTO test_transaction (id) VALUES(gen_random_uuid());")
+		return err
+	}
+
+	err = o.Transaction(ctx, func(e *Executor) error {
+		if err := insert(ctx, e); err != nil {
+			return err
+		}
+
+		err := e.Transaction(ctx, func(e *Executor) error {
+			if err := insert(ctx, e); err != nil {
+				return err
+			}
+
+			return errors.New("some error")
+		})
+		if err == nil {
+			t.Error("expected the error of the nested transaction")
+		}
+
+		return e.Transaction(ctx, func(e *Executor) error {
+			return e.Transaction(ctx, func(e *Executor) error {
+				return insert(ctx, e)
+			})
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := o.QueryRowContext(ctx, "SELECT count(*) FROM test_transaction;").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2, but got %d", count)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	if _, err := Runner(ctx, nil); !errors.Is(err, ErrNoQueryRunner) {
+		t.Errorf("expected ErrNoQueryRunner, got %v", err)
+	}
+
+	o := &Orm{}
+	if qr, err := Runner(ctx, o); err != nil || qr != o {
+		t.Errorf("expected qr to be returned, got %v %v", qr, err)
+	}
+
+	ex := NewExecutor(o)
+	if qr, err := Runner(gooocontext.WithTx(ctx, ex), nil); err != nil || qr != ex {
+		t.Errorf("expected the transaction of ctx, got %v %v", qr, err)
+	}
+}
+
+func TestAmbientTransaction(t *testing.T) {
+	db, err := sqlx.Connect("postgres", os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	o := New(db, &logging.MockLogger{}, Options{QueryLog: true})
+	ctx := context.Background()
+
+	if _, err := o.ExecContext(ctx, "DELETE FROM test_transaction;"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := o.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = gooocontext.WithTx(ctx, tx)
+	qr, err := Runner(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := qr.ExecContext(ctx, "INSERT INTO test_transaction (id) VALUES(gen_random_uuid());"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Atomic(ctx, nil, func(qr QueryRunner) error {
+		if _, err := qr.ExecContext(ctx, "INSERT INTO test_transaction (id) VALUES(gen_random_uuid());"); err != nil {
+			return err
+		}
+
+		return errors.New("some error")
+	})
+	if err == nil {
+		t.Error("expected the error of the nested transaction")
+	}
+
+	var count int
+	if err := qr.QueryRowContext(ctx, "SELECT count(*) FROM test_transaction;").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 in the transaction, but got %d", count)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.QueryRowContext(ctx, "SELECT count(*) FROM test_transaction;").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expected 0, but got %d", count)
+	}
+}
+
+func TestTransactionRetry(t *testing.T) {
+	db, err := sqlx.Connect("postgres", os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	o := New(db, &logging.MockLogger{}, Options{QueryLog: true})
+	ctx := context.Background()
+
+	if _, err := o.ExecContext(ctx, "DELETE FROM test_transaction;"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := TxOptions{
+		Isolation:  sql.LevelSerializable,
+		MaxRetries: 2,
+		Backoff:    func(int) time.Duration { return time.Millisecond },
+	}
+
+	attempts := 0
+	err = o.Transaction(ctx, func(e *Executor) error {
+		attempts++
+		if _, err := e.ExecContext(ctx, "INSERT INTO test_transaction (id) VALUES(gen_random_uuid());"); err != nil {
+			return err
+		}
+
+		if attempts < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, but got %d", attempts)
+	}
+
+	var count int
+	if err := o.QueryRowContext(ctx, "SELECT count(*) FROM test_transaction;").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1, but got %d", count)
+	}
+
+	attempts = 0
+	err = o.Transaction(ctx, func(e *Executor) error {
+		attempts++
+		return &pq.Error{Code: "40P01"}
+	}, opts)
+	if !IsRetryable(err) || attempts != 3 {
+		t.Fatalf("expected the deadlock after 3 attempts, but got %v after %d", err, attempts)
+	}
+}
@@ -0,0 +1,109 @@
+package orm
+
+import "reflect"
+
+// Snapshot is the column values of a record as it was last scanned. It gives
+// the schemas dirty tracking: the generated Update writes only the columns
+// changed since then. The generator adds a snapshot field to every schema
+// which does not declare a Snapshot field.
+//
+//	type User struct {
+//		snapshot orm.Snapshot
+//
+//		ID    int    `json:"id" gooo:"primary_key,immutable"`
+//		Email string `json:"email"`
+//	}
+type Snapshot struct {
+	values map[string]any
+}
+
+// Take records copies of the values of the columns, so that a value changed
+// in place through a pointer, slice or map is still detected.
+func (s *Snapshot) Take(columns []string, values []any) {
+	s.values = make(map[string]any, len(columns))
+	for i, c := range columns {
+		if values[i] == nil {
+			s.values[c] = nil
+			continue
+		}
+
+		s.values[c] = deepCopy(reflect.ValueOf(values[i])).Interface()
+	}
+}
+
+// Taken reports whether a snapshot has been taken.
+func (s Snapshot) Taken() bool {
+	return s.values != nil
+}
+
+// Changed reports whether v differs from the value of column in the snapshot.
+// Every column is changed until a snapshot is taken.
+func (s Snapshot) Changed(column string, v any) bool {
+	old, ok := s.values[column]
+	if !ok {
+		return true
+	}
+
+	return !reflect.DeepEqual(old, v)
+}
+
+// deepCopy copies v along its pointers, slices, maps and exported struct
+// fields. Unexported fields are copied by value.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	s := Snapshot{}
+	if s.Taken() || !s.Changed("email", "a@example.com") {
+		t.Fatal("expected every column to be changed before a snapshot is taken")
+	}
+
+	now := time.Now()
+	s.Take([]string{"email", "created_at"}, []any{"a@example.com", now})
+	if !s.Taken() {
+		t.Fatal("expected the snapshot to be taken")
+	}
+
+	if s.Changed("email", "a@example.com") || s.Changed("created_at", now) {
+		t.Fatal("expected unchanged values not to be changed")
+	}
+
+	if !s.Changed("email", "b@example.com") || !s.Changed("timezone", "UTC") {
+		t.Fatal("expected changed and unknown columns to be changed")
+	}
+}
+
+func TestSnapshotCopiesValues(t *testing.T) {
+	name := "gooo"
+	tags := []string{"go"}
+	meta := map[string]any{"theme": "dark"}
+
+	s := Snapshot{}
+	s.Take([]string{"name", "tags", "meta", "deleted_at"}, []any{&name, tags, meta, nil})
+
+	name = "gooo2"
+	tags[0] = "rust"
+	meta["theme"] = "light"
+
+	for _, test := range []struct {
+		column string
+		value  any
+	}{
+		{"name", &name},
+		{"tags", tags},
+		{"meta", meta},
+	} {
+		if !s.Changed(test.column, test.value) {
+			t.Errorf("expected %s changed in place to be changed", test.column)
+		}
+	}
+
+	if s.Changed("deleted_at", nil) {
+		t.Error("expected nil not to be changed")
+	}
+}
@@ -0,0 +1,87 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TxOptions configures a transaction run by Transaction. A transaction failing
+// with a serialization failure or a deadlock is retried up to MaxRetries
+// times, waiting Backoff between attempts, which defaults to DefaultBackoff.
+// The whole callback runs again on retry, so it must not have side effects
+// outside of the transaction.
+type TxOptions struct {
+	Isolation  sql.IsolationLevel
+	ReadOnly   bool
+	MaxRetries int
+	Backoff    func(attempt int) time.Duration
+}
+
+// DefaultBackoff waits from 10ms up to 1s.
+var DefaultBackoff = ExponentialBackoff(10*time.Millisecond, time.Second)
+
+// ExponentialBackoff doubles the wait from base on each attempt up to max. A
+// random jitter of up to half of the wait spreads the retries of transactions
+// conflicting with each other.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 0; i < attempt && d < max; i++ {
+			d *= 2
+		}
+
+		if d > max {
+			d = max
+		}
+
+		if half := int64(d / 2); half > 0 {
+			d = d/2 + time.Duration(rand.Int63n(half))
+		}
+
+		return d
+	}
+}
+
+func (o TxOptions) sqlOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: o.Isolation, ReadOnly: o.ReadOnly}
+}
+
+// wait sleeps before the retry of attempt and returns the error of ctx when
+// it is done first.
+func (o TxOptions) wait(ctx context.Context, attempt int) error {
+	backoff := o.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	t := time.NewTimer(backoff(attempt))
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// IsRetryable reports whether err is a serialization failure or a deadlock,
+// after which Postgres expects the client to retry the transaction.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
@@ -0,0 +1,194 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	gooocontext "github.com/version-1/gooo/pkg/context"
+	"github.com/version-1/gooo/pkg/datasource/logging"
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect bool
+	}{
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, expect: true},
+		{name: "deadlock", err: &pq.Error{Code: "40P01"}, expect: true},
+		{name: "wrapped", err: goooerrors.Wrap(fmt.Errorf("update: %w", &pq.Error{Code: "40001"})), expect: true},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}, expect: false},
+		{name: "other error", err: errors.New("some error"), expect: false},
+		{name: "nil", err: nil, expect: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsRetryable(test.err); got != test.expect {
+				t.Errorf("expect %t, got %t", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 100*time.Millisecond)
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 0, min: 5 * time.Millisecond, max: 10 * time.Millisecond},
+		{attempt: 2, min: 20 * time.Millisecond, max: 40 * time.Millisecond},
+		{attempt: 10, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		if d := backoff(test.attempt); d < test.min || d > test.max {
+			t.Errorf("attempt %d: expect between %s and %s, got %s", test.attempt, test.min, test.max, d)
+		}
+	}
+}
+
+// txDriver is a database/sql driver whose connections only begin
+// transactions and execute savepoint statements. Rolling back, to a savepoint
+// too, fails with rollbackErr.
+type txDriver struct {
+	rollbacks   *int
+	rollbackErr error
+}
+
+func (d txDriver) Open(name string) (driver.Conn, error) { return d, nil }
+func (d txDriver) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (d txDriver) Close() error              { return nil }
+func (d txDriver) Begin() (driver.Tx, error) { return d, nil }
+func (d txDriver) Commit() error             { return nil }
+func (d txDriver) Rollback() error {
+	*d.rollbacks++
+	return d.rollbackErr
+}
+
+func (d txDriver) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.HasPrefix(query, "ROLLBACK TO SAVEPOINT ") {
+		return nil, d.Rollback()
+	}
+
+	return driver.RowsAffected(0), nil
+}
+
+func TestTransactionRollbackError(t *testing.T) {
+	rollbacks := 0
+	rollbackErr := errors.New("rollback failed")
+	o := New(sqlx.NewDb(sql.OpenDB(connector{txDriver{&rollbacks, rollbackErr}}), "postgres"), &logging.MockLogger{}, Options{})
+	ctx := context.Background()
+
+	fnErr := errors.New("some error")
+	err := o.Transaction(ctx, func(e *Executor) error {
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) || !errors.Is(err, rollbackErr) {
+		t.Errorf("expected the errors of fn and rollback, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("expected the panic to propagate, got %v", v)
+			}
+		}()
+
+		o.Transaction(ctx, func(e *Executor) error {
+			panic("boom")
+		})
+	}()
+
+	if rollbacks != 2 {
+		t.Errorf("expected 2 rollbacks, got %d", rollbacks)
+	}
+}
+
+func TestNestedTransactionRollbackError(t *testing.T) {
+	rollbacks := 0
+	rollbackErr := errors.New("rollback failed")
+	o := New(sqlx.NewDb(sql.OpenDB(connector{txDriver{&rollbacks, rollbackErr}}), "postgres"), &logging.MockLogger{}, Options{})
+	ctx := context.Background()
+
+	ex, err := o.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ex.Rollback()
+
+	fnErr := errors.New("some error")
+	err = ex.Transaction(ctx, func(e *Executor) error {
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) || !errors.Is(err, rollbackErr) {
+		t.Errorf("expected the errors of fn and rollback, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("expected the panic to propagate, got %v", v)
+			}
+		}()
+
+		ex.Transaction(ctx, func(e *Executor) error {
+			panic("boom")
+		})
+	}()
+
+	if rollbacks != 2 {
+		t.Errorf("expected 2 rollbacks to the savepoint, got %d", rollbacks)
+	}
+}
+
+func TestTransactionWithContextTx(t *testing.T) {
+	rollbacks := 0
+	o := New(sqlx.NewDb(sql.OpenDB(connector{txDriver{rollbacks: &rollbacks}}), "postgres"), &logging.MockLogger{}, Options{})
+	ctx := context.Background()
+
+	ex, err := o.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ex.Rollback()
+
+	ctx = gooocontext.WithTx(ctx, ex)
+	if err := o.Transaction(ctx, func(e *Executor) error {
+		if e.tx == ex.tx {
+			t.Error("expected a new transaction for the explicit runner")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Atomic(ctx, nil, func(qr QueryRunner) error {
+		if e, ok := qr.(*Executor); !ok || e.tx != ex.tx {
+			t.Errorf("expected the transaction of ctx, got %v", qr)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type connector struct {
+	d txDriver
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) { return c.d, nil }
+func (c connector) Driver() driver.Driver                            { return c.d }
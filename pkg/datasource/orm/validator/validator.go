@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 
 	"github.com/version-1/gooo/pkg/datasource/orm/errors"
@@ -20,19 +21,33 @@ func Required(k string) ValidatorFunc {
 	}
 }
 
+// NotEmpty is stricter than Required and rejects the zero value of any type,
+// e.g. 0, false and "", which are legitimate values of a model.
+func NotEmpty(k string) ValidatorFunc {
+	return func(v ...any) errors.ValidationError {
+		if len(v) == 0 || IsEmpty(v[0]) {
+			return errors.NewRequiredError(k)
+		}
+
+		return nil
+	}
+}
+
 func OneOf(values []fmt.Stringer) Validator {
 	return func(key string) ValidatorFunc {
 		return func(v ...any) errors.ValidationError {
-			for i := range values {
-				ele := values[i].String()
-				vv := stringify(v)
+			if len(v) == 0 {
+				return nil
+			}
 
-				if ele != vv {
-					return errors.NewMustOneOfError(key, values, vv)
+			vv := stringify(v[0])
+			for i := range values {
+				if values[i].String() == vv {
+					return nil
 				}
 			}
 
-			return nil
+			return errors.NewMustOneOfError(key, values, vv)
 		}
 	}
 }
@@ -56,6 +71,15 @@ func Format(r *regexp.Regexp) Validator {
 
 var Email = Format(regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`))
 
+// IsEmpty reports whether v is nil or the zero value of its type.
+func IsEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	return reflect.ValueOf(v).IsZero()
+}
+
 func stringify(v any) string {
 	switch vv := v.(type) {
 	case string:
@@ -0,0 +1,30 @@
+package validator
+
+import "testing"
+
+func TestRequiredAndNotEmpty(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []any
+		required bool
+		notEmpty bool
+	}{
+		{name: "no value", values: nil, required: false, notEmpty: false},
+		{name: "zero int", values: []any{0}, required: true, notEmpty: false},
+		{name: "false", values: []any{false}, required: true, notEmpty: false},
+		{name: "empty string", values: []any{""}, required: true, notEmpty: false},
+		{name: "value", values: []any{"gooo"}, required: true, notEmpty: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ok := Required("key")(test.values...) == nil; ok != test.required {
+				t.Errorf("Required: expect valid %t, got %t", test.required, ok)
+			}
+
+			if ok := NotEmpty("key")(test.values...) == nil; ok != test.notEmpty {
+				t.Errorf("NotEmpty: expect valid %t, got %t", test.notEmpty, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package query
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Expression is a fragment of SQL. Arguments are written through the Buffer,
+// which numbers the $n placeholders in the order they appear.
+type Expression interface {
+	WriteSQL(b *Buffer)
+}
+
+type Buffer struct {
+	strings.Builder
+	args []any
+}
+
+// Arg writes a placeholder for v. Expressions are embedded as SQL instead.
+func (b *Buffer) Arg(v any) {
+	if e, ok := v.(Expression); ok {
+		e.WriteSQL(b)
+		return
+	}
+
+	b.args = append(b.args, v)
+	b.WriteString("$" + strconv.Itoa(len(b.args)))
+}
+
+func (b *Buffer) Args() []any {
+	return b.args
+}
+
+// Build renders e with its arguments.
+func Build(e Expression) (string, []any) {
+	b := &Buffer{}
+	e.WriteSQL(b)
+
+	return b.String(), b.Args()
+}
+
+type raw struct {
+	sql  string
+	args []any
+}
+
+// Raw is a SQL fragment with ? placeholders for args. ?? is a literal ?.
+//
+//	query.Raw("posts.user_id = users.id")
+//	query.Raw("created_at > NOW() - ?::interval", "1 day")
+func Raw(sql string, args ...any) Expression {
+	return raw{sql: sql, args: args}
+}
+
+func (r raw) WriteSQL(b *Buffer) {
+	n := 0
+	for i := 0; i < len(r.sql); i++ {
+		c := r.sql[i]
+		if c == '?' {
+			if i+1 < len(r.sql) && r.sql[i+1] == '?' {
+				b.WriteByte('?')
+				i++
+				continue
+			}
+
+			if n < len(r.args) {
+				b.Arg(r.args[n])
+				n++
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+}
+
+type compare struct {
+	column string
+	op     string
+	value  any
+}
+
+func (c compare) WriteSQL(b *Buffer) {
+	b.WriteString(c.column)
+	b.WriteString(" " + c.op + " ")
+	b.Arg(c.value)
+}
+
+func Eq(column string, v any) Expression    { return compare{column, "=", v} }
+func Ne(column string, v any) Expression    { return compare{column, "<>", v} }
+func Gt(column string, v any) Expression    { return compare{column, ">", v} }
+func Gte(column string, v any) Expression   { return compare{column, ">=", v} }
+func Lt(column string, v any) Expression    { return compare{column, "<", v} }
+func Lte(column string, v any) Expression   { return compare{column, "<=", v} }
+func Like(column string, v any) Expression  { return compare{column, "LIKE", v} }
+func ILike(column string, v any) Expression { return compare{column, "ILIKE", v} }
+
+type null struct {
+	column string
+	not    bool
+}
+
+func (n null) WriteSQL(b *Buffer) {
+	b.WriteString(n.column)
+	if n.not {
+		b.WriteString(" IS NOT NULL")
+	} else {
+		b.WriteString(" IS NULL")
+	}
+}
+
+func IsNull(column string) Expression    { return null{column: column} }
+func IsNotNull(column string) Expression { return null{column: column, not: true} }
+
+type in struct {
+	column string
+	not    bool
+	values []any
+}
+
+// In matches column against the values, a slice of them or a subquery.
+//
+//	query.In("id", 1, 2, 3)
+//	query.In("id", ids)
+//	query.In("user_id", query.Select("id").From("users"))
+func In(column string, values ...any) Expression {
+	return in{column: column, values: flatten(values)}
+}
+
+func NotIn(column string, values ...any) Expression {
+	return in{column: column, not: true, values: flatten(values)}
+}
+
+func (i in) WriteSQL(b *Buffer) {
+	if len(i.values) == 0 {
+		// IN () is a syntax error. An empty list matches nothing.
+		if i.not {
+			b.WriteString("1 = 1")
+		} else {
+			b.WriteString("1 = 0")
+		}
+		return
+	}
+
+	b.WriteString(i.column)
+	if i.not {
+		b.WriteString(" NOT")
+	}
+	b.WriteString(" IN (")
+	for j, v := range i.values {
+		if j > 0 {
+			b.WriteString(", ")
+		}
+		b.Arg(v)
+	}
+	b.WriteString(")")
+}
+
+func flatten(values []any) []any {
+	if len(values) != 1 {
+		return values
+	}
+
+	rv := reflect.ValueOf(values[0])
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return values
+	}
+
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+
+	return list
+}
+
+type exists struct {
+	query *SelectQuery
+	not   bool
+}
+
+func (e exists) WriteSQL(b *Buffer) {
+	if e.not {
+		b.WriteString("NOT ")
+	}
+	b.WriteString("EXISTS (")
+	e.query.WriteSQL(b)
+	b.WriteString(")")
+}
+
+func Exists(q *SelectQuery) Expression    { return exists{query: q} }
+func NotExists(q *SelectQuery) Expression { return exists{query: q, not: true} }
+
+type not struct {
+	expr Expression
+}
+
+func (n not) WriteSQL(b *Buffer) {
+	b.WriteString("NOT (")
+	n.expr.WriteSQL(b)
+	b.WriteString(")")
+}
+
+func Not(e Expression) Expression { return not{expr: e} }
+
+type junction struct {
+	op    string
+	exprs []Expression
+}
+
+// And joins the conditions with AND. Nil conditions are skipped.
+func And(exprs ...Expression) Expression { return junction{op: "AND", exprs: exprs} }
+
+// Or joins the conditions with OR. Nil conditions are skipped.
+func Or(exprs ...Expression) Expression { return junction{op: "OR", exprs: exprs} }
+
+func (j junction) WriteSQL(b *Buffer) {
+	exprs := compact(j.exprs)
+	switch len(exprs) {
+	case 0:
+		if j.op == "AND" {
+			b.WriteString("1 = 1")
+		} else {
+			b.WriteString("1 = 0")
+		}
+	case 1:
+		exprs[0].WriteSQL(b)
+	default:
+		b.WriteString("(")
+		writeJoined(b, j.op, exprs)
+		b.WriteString(")")
+	}
+}
+
+func writeJoined(b *Buffer, op string, exprs []Expression) {
+	for i, e := range exprs {
+		if i > 0 {
+			b.WriteString(" " + op + " ")
+		}
+
+		if _, ok := e.(raw); ok && len(exprs) > 1 {
+			b.WriteString("(")
+			e.WriteSQL(b)
+			b.WriteString(")")
+			continue
+		}
+
+		e.WriteSQL(b)
+	}
+}
+
+func compact(exprs []Expression) []Expression {
+	list := make([]Expression, 0, len(exprs))
+	for _, e := range exprs {
+		if e != nil {
+			list = append(list, e)
+		}
+	}
+
+	return list
+}
+
+// Excluded refers to the row proposed for insertion in ON CONFLICT DO UPDATE.
+func Excluded(column string) Expression {
+	return Raw("EXCLUDED." + column)
+}
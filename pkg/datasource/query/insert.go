@@ -0,0 +1,207 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/version-1/gooo/pkg/datasource/orm"
+)
+
+var _ Expression = &InsertQuery{}
+
+type assignment struct {
+	column string
+	value  any
+}
+
+// InsertQuery builds an INSERT statement with an optional upsert.
+//
+//	query.InsertInto("users").
+//		Columns("email", "username").
+//		Values(email, username).
+//		OnConflict("email").
+//		DoUpdate("username").
+//		Returning("id")
+type InsertQuery struct {
+	table      string
+	columns    []string
+	rows       [][]any
+	onConflict bool
+	conflict   []string
+	updates    []assignment
+	conflictOn conditions
+	returning  []string
+}
+
+func InsertInto(table string) *InsertQuery {
+	return &InsertQuery{table: table}
+}
+
+func (q *InsertQuery) Columns(columns ...string) *InsertQuery {
+	q.columns = append(q.columns, columns...)
+	return q
+}
+
+// Values adds a row. Call it once per row.
+func (q *InsertQuery) Values(values ...any) *InsertQuery {
+	q.rows = append(q.rows, values)
+	return q
+}
+
+// OnConflict starts the upsert clause on the conflict target columns.
+func (q *InsertQuery) OnConflict(target ...string) *InsertQuery {
+	q.onConflict = true
+	q.conflict = target
+	return q
+}
+
+func (q *InsertQuery) DoNothing() *InsertQuery {
+	q.onConflict = true
+	q.updates = nil
+	return q
+}
+
+// DoUpdate overwrites the columns with the values proposed for insertion.
+func (q *InsertQuery) DoUpdate(columns ...string) *InsertQuery {
+	q.onConflict = true
+	for _, c := range columns {
+		q.updates = append(q.updates, assignment{column: c, value: Excluded(c)})
+	}
+
+	return q
+}
+
+// DoUpdateSet sets column to v on conflict. v may be an Expression.
+func (q *InsertQuery) DoUpdateSet(column string, v any) *InsertQuery {
+	q.onConflict = true
+	q.updates = append(q.updates, assignment{column: column, value: v})
+	return q
+}
+
+// Where limits the rows updated on conflict.
+func (q *InsertQuery) Where(conds ...Expression) *InsertQuery {
+	q.conflictOn.and(conds)
+	return q
+}
+
+func (q *InsertQuery) Returning(columns ...string) *InsertQuery {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+func (q *InsertQuery) WriteSQL(b *Buffer) {
+	b.WriteString("INSERT INTO " + q.table)
+	if len(q.columns) > 0 {
+		b.WriteString(" (" + strings.Join(q.columns, ", ") + ")")
+	}
+
+	if len(q.rows) == 0 {
+		b.WriteString(" DEFAULT VALUES")
+	} else {
+		b.WriteString(" VALUES ")
+	}
+
+	for i, row := range q.rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString("(")
+		for j, v := range row {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.Arg(v)
+		}
+		b.WriteString(")")
+	}
+
+	if q.onConflict {
+		b.WriteString(" ON CONFLICT")
+		if len(q.conflict) > 0 {
+			b.WriteString(" (" + strings.Join(q.conflict, ", ") + ")")
+		}
+
+		if len(q.updates) == 0 {
+			b.WriteString(" DO NOTHING")
+		} else {
+			b.WriteString(" DO UPDATE")
+			writeSet(b, q.updates)
+			q.conflictOn.write(b, "WHERE")
+		}
+	}
+
+	writeReturning(b, q.returning)
+}
+
+// Build renders the statement. DoUpdate without a conflict target is an
+// error.
+func (q *InsertQuery) Build() (string, []any, error) {
+	if len(q.updates) > 0 && len(q.conflict) == 0 {
+		return "", nil, ErrConflictTargetRequired
+	}
+
+	s, args := Build(q)
+	return s, args, nil
+}
+
+func (q *InsertQuery) Exec(ctx context.Context, qr orm.QueryRunner) (sql.Result, error) {
+	s, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.ExecContext(ctx, s, args...)
+}
+
+func (q *InsertQuery) Query(ctx context.Context, qr orm.QueryRunner) (*sql.Rows, error) {
+	s, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.QueryContext(ctx, s, args...)
+}
+
+func (q *InsertQuery) QueryRow(ctx context.Context, qr orm.QueryRunner) *Row {
+	s, args, err := q.Build()
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	return &Row{row: qr.QueryRowContext(ctx, s, args...)}
+}
+
+func writeSet(b *Buffer, list []assignment) {
+	b.WriteString(" SET ")
+	for i, a := range list {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(a.column + " = ")
+		b.Arg(a.value)
+	}
+}
+
+func writeReturning(b *Buffer, columns []string) {
+	if len(columns) > 0 {
+		b.WriteString(" RETURNING " + strings.Join(columns, ", "))
+	}
+}
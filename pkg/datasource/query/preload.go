@@ -0,0 +1,62 @@
+package query
+
+import "strings"
+
+// Preload is an association to load with the association paths nested in it.
+type Preload struct {
+	Name   string
+	Nested []string
+}
+
+// Preloads groups the dot-separated association paths by their first
+// association, in the order they first appear.
+//
+//	query.Preloads("Posts", "Posts.Likes", "Profile")
+//	// [{Posts [Likes]} {Profile []}]
+func Preloads(paths ...string) []Preload {
+	list := []Preload{}
+	index := map[string]int{}
+	for _, p := range paths {
+		name, nested, _ := strings.Cut(p, ".")
+		i, ok := index[name]
+		if !ok {
+			i = len(list)
+			index[name] = i
+			list = append(list, Preload{Name: name, Nested: []string{}})
+		}
+
+		if nested != "" {
+			list[i].Nested = append(list[i].Nested, nested)
+		}
+	}
+
+	return list
+}
+
+// Keys returns the distinct keys of list, in order. It builds the IN list of
+// a preload.
+func Keys[K comparable, T any](list []T, key func(T) K) []K {
+	keys := []K{}
+	seen := map[K]bool{}
+	for _, v := range list {
+		k := key(v)
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// Group indexes list by key. It stitches preloaded records into their
+// owners.
+func Group[K comparable, T any](list []T, key func(T) K) map[K][]T {
+	m := map[K][]T{}
+	for _, v := range list {
+		k := key(v)
+		m[k] = append(m[k], v)
+	}
+
+	return m
+}
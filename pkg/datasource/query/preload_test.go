@@ -0,0 +1,40 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreloads(t *testing.T) {
+	got := Preloads("Posts", "Profile", "Posts.Likes", "Posts.Likes.Likeable", "Posts.User")
+	expect := []Preload{
+		{Name: "Posts", Nested: []string{"Likes", "Likes.Likeable", "User"}},
+		{Name: "Profile", Nested: []string{}},
+	}
+
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %v, got %v", expect, got)
+	}
+}
+
+func TestKeysAndGroup(t *testing.T) {
+	type post struct {
+		ID     int
+		UserID int
+	}
+
+	list := []post{{ID: 1, UserID: 2}, {ID: 2, UserID: 1}, {ID: 3, UserID: 2}}
+	userID := func(p post) int { return p.UserID }
+
+	if keys := Keys(list, userID); !reflect.DeepEqual(keys, []int{2, 1}) {
+		t.Errorf("expect [2 1], got %v", keys)
+	}
+
+	expect := map[int][]post{
+		1: {{ID: 2, UserID: 1}},
+		2: {{ID: 1, UserID: 2}, {ID: 3, UserID: 2}},
+	}
+	if m := Group(list, userID); !reflect.DeepEqual(m, expect) {
+		t.Errorf("expect %v, got %v", expect, m)
+	}
+}
@@ -1,62 +1,59 @@
+// Package query builds parameterized SQL for PostgreSQL. The $n placeholders
+// are numbered automatically in the order the arguments are written, and the
+// queries run through orm.QueryRunner.
+//
+//	rows, err := query.Select("id", "email").
+//		From("users").
+//		Where(query.Eq("status", "active")).
+//		Or(query.In("id", ids)).
+//		OrderBy("created_at DESC").
+//		Limit(10).
+//		Query(ctx, qr)
 package query
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
+
+	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
 )
 
-func Select(table string, fields []string, where *string) string {
-	cond := "1 = 1"
-	if where != nil {
-		cond = *where
-	}
+var (
+	// ErrWhereRequired is returned when an UPDATE or DELETE has no where
+	// clause. Call All to write every row.
+	ErrWhereRequired = errors.New("where clause is required")
+	// ErrSetRequired is returned when an UPDATE assigns no column.
+	ErrSetRequired = errors.New("set clause is required")
+	// ErrConflictTargetRequired is returned when ON CONFLICT DO UPDATE has no
+	// conflict target, which PostgreSQL requires.
+	ErrConflictTargetRequired = errors.New("conflict target is required for do update")
+)
 
-	return fmt.Sprintf(
-		"SELECT %s FROM %s WHERE %s",
-		strings.Join(fields, ","),
-		table,
-		cond,
-	)
+// Row is the result of QueryRow. An error building the query is returned
+// from Scan, like the errors of the query itself, which are translated with
+// ormerrors.Translate.
+type Row struct {
+	row *sql.Row
+	err error
 }
 
-func Insert(table string, fields []string, returnings *[]string) string {
-	rtns := []string{"id", "created_at", "updated_at"}
-	if returnings != nil {
-		rtns = *returnings
+func (r *Row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
 	}
 
-	placeholders := BuildPlaceholders(len(fields))
-
-	return fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
-		table,
-		strings.Join(fields, ","),
-		placeholders,
-		strings.Join(rtns, ","),
-	)
+	return ormerrors.Translate(r.row.Scan(dest...))
 }
 
-func Update(table string, fields []string, condition string) (string, error) {
-	if condition == "" {
-		return "", errors.New("where clause is required")
-	}
-
-	set := ""
-	for i, f := range fields {
-		if i == len(fields)-1 {
-			set += fmt.Sprintf("%s = $%d", f, i+1)
-		} else {
-			set += fmt.Sprintf("%s = $%d, ", f, i+1)
-		}
+// Err returns the error building or running the query, if any.
+func (r *Row) Err() error {
+	if r.err != nil {
+		return r.err
 	}
 
-	return fmt.Sprintf(
-		"UPDATE %s SET %s WHERE %s",
-		table,
-		strings.Join(fields, ","),
-		condition,
-	), nil
+	return ormerrors.Translate(r.row.Err())
 }
 
 func BuildPlaceholders(n int) string {
@@ -65,5 +62,5 @@ func BuildPlaceholders(n int) string {
 		list = append(list, fmt.Sprintf("$%d", i))
 	}
 
-	return fmt.Sprintf("%s", strings.Join(list, ", "))
+	return strings.Join(list, ", ")
 }
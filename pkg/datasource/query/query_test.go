@@ -0,0 +1,224 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/version-1/gooo/pkg/datasource/orm"
+)
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  Expression
+		expect string
+		args   []any
+	}{
+		{
+			name:   "all",
+			query:  Select().From("users"),
+			expect: "SELECT * FROM users",
+		},
+		{
+			name: "where, and, or",
+			query: Select("id", "email").
+				From("users").
+				Where(Eq("status", "active"), Gt("age", 18)).
+				Or(In("id", []int{1, 2})),
+			expect: "SELECT id, email FROM users WHERE ((status = $1 AND age > $2) OR id IN ($3, $4))",
+			args:   []any{"active", 18, 1, 2},
+		},
+		{
+			name: "joins, group, having, order, limit and offset",
+			query: Select("users.id", "count(posts.id)").
+				From("users").
+				LeftJoin("posts", Raw("posts.user_id = users.id")).
+				Where(IsNull("users.deleted_at")).
+				GroupBy("users.id").
+				Having(Raw("count(posts.id) > ?", 3)).
+				OrderBy("users.id DESC").
+				Limit(10).
+				Offset(20),
+			expect: "SELECT users.id, count(posts.id) FROM users LEFT JOIN posts ON posts.user_id = users.id WHERE users.deleted_at IS NULL GROUP BY users.id HAVING count(posts.id) > $1 ORDER BY users.id DESC LIMIT $2 OFFSET $3",
+			args:   []any{3, 10, 20},
+		},
+		{
+			name: "subqueries",
+			query: Select("id").
+				From("users").
+				Where(
+					Eq("status", "active"),
+					In("id", Select("user_id").From("posts").Where(Like("title", "%go%"))),
+					NotExists(Select("1").From("bans").Where(Raw("bans.user_id = users.id AND bans.until > ?", "now"))),
+				),
+			expect: "SELECT id FROM users WHERE status = $1 AND id IN (SELECT user_id FROM posts WHERE title LIKE $2) AND NOT EXISTS (SELECT 1 FROM bans WHERE bans.user_id = users.id AND bans.until > $3)",
+			args:   []any{"active", "%go%", "now"},
+		},
+		{
+			name:   "from subquery and empty in",
+			query:  Select("count(*)").FromSubquery(Select("id").From("users").Where(In("id")), "u"),
+			expect: "SELECT count(*) FROM (SELECT id FROM users WHERE 1 = 0) AS u",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, args := Build(test.query)
+			if s != test.expect {
+				t.Errorf("expect %s, got %s", test.expect, s)
+			}
+
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("expect %v, got %v", test.args, args)
+			}
+		})
+	}
+}
+
+func TestInsertUpdateDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  Expression
+		expect string
+		args   []any
+	}{
+		{
+			name: "insert rows",
+			query: InsertInto("users").
+				Columns("email", "username").
+				Values("a@example.com", "a").
+				Values("b@example.com", "b").
+				Returning("id", "created_at"),
+			expect: "INSERT INTO users (email, username) VALUES ($1, $2), ($3, $4) RETURNING id, created_at",
+			args:   []any{"a@example.com", "a", "b@example.com", "b"},
+		},
+		{
+			name: "upsert",
+			query: InsertInto("users").
+				Columns("email", "username").
+				Values("a@example.com", "a").
+				OnConflict("email").
+				DoUpdate("username").
+				DoUpdateSet("updated_at", Raw("NOW()")).
+				Where(Ne("users.username", "admin")),
+			expect: "INSERT INTO users (email, username) VALUES ($1, $2) ON CONFLICT (email) DO UPDATE SET username = EXCLUDED.username, updated_at = NOW() WHERE users.username <> $3",
+			args:   []any{"a@example.com", "a", "admin"},
+		},
+		{
+			name:   "insert or ignore",
+			query:  InsertInto("likes").Columns("user_id").Values(1).OnConflict().DoNothing(),
+			expect: "INSERT INTO likes (user_id) VALUES ($1) ON CONFLICT DO NOTHING",
+			args:   []any{1},
+		},
+		{
+			name: "update",
+			query: Update("users").
+				Set("email", "a@example.com").
+				Set("updated_at", Raw("NOW()")).
+				Where(Eq("id", 1)).
+				Returning("updated_at"),
+			expect: "UPDATE users SET email = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at",
+			args:   []any{"a@example.com", 1},
+		},
+		{
+			name:   "delete",
+			query:  DeleteFrom("users").Where(Eq("id", 1)).Or(Not(IsNotNull("deleted_at"))),
+			expect: "DELETE FROM users WHERE (id = $1 OR NOT (deleted_at IS NOT NULL))",
+			args:   []any{1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, args := Build(test.query)
+			if s != test.expect {
+				t.Errorf("expect %s, got %s", test.expect, s)
+			}
+
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("expect %v, got %v", test.args, args)
+			}
+		})
+	}
+}
+
+type statement interface {
+	Build() (string, []any, error)
+}
+
+func TestStatementBuild(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  statement
+		expect string
+		err    error
+	}{
+		{
+			name:  "update without where",
+			query: Update("users").Set("status", "inactive"),
+			err:   ErrWhereRequired,
+		},
+		{
+			name:   "update all",
+			query:  Update("users").Set("status", "inactive").All(),
+			expect: "UPDATE users SET status = $1",
+		},
+		{
+			name:  "update without set",
+			query: Update("users").Where(Eq("id", 1)),
+			err:   ErrSetRequired,
+		},
+		{
+			name:  "delete without where",
+			query: DeleteFrom("users"),
+			err:   ErrWhereRequired,
+		},
+		{
+			name:   "delete all",
+			query:  DeleteFrom("users").All(),
+			expect: "DELETE FROM users",
+		},
+		{
+			name:  "do update without target",
+			query: InsertInto("users").Columns("email").Values("a@example.com").OnConflict().DoUpdate("email"),
+			err:   ErrConflictTargetRequired,
+		},
+		{
+			name:   "do nothing without target",
+			query:  InsertInto("users").Columns("email").Values("a@example.com").OnConflict().DoNothing(),
+			expect: "INSERT INTO users (email) VALUES ($1) ON CONFLICT DO NOTHING",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _, err := test.query.Build()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expect error %v, got %v", test.err, err)
+			}
+
+			if s != test.expect {
+				t.Errorf("expect %s, got %s", test.expect, s)
+			}
+		})
+	}
+
+	var n int
+	if err := Update("users").QueryRow(context.Background(), nil).Scan(&n); !errors.Is(err, ErrSetRequired) {
+		t.Errorf("expect QueryRow to return the build error, got %v", err)
+	}
+}
+
+func TestNoQueryRunner(t *testing.T) {
+	ctx := context.Background()
+	var n int
+	if err := Select("count(*)").From("users").QueryRow(ctx, nil).Scan(&n); !errors.Is(err, orm.ErrNoQueryRunner) {
+		t.Errorf("expected ErrNoQueryRunner, got %v", err)
+	}
+
+	if _, err := DeleteFrom("users").Where(Eq("id", 1)).Exec(ctx, nil); !errors.Is(err, orm.ErrNoQueryRunner) {
+		t.Errorf("expected ErrNoQueryRunner, got %v", err)
+	}
+}
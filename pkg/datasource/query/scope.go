@@ -0,0 +1,254 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/version-1/gooo/pkg/datasource/orm"
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+)
+
+type Scanner interface {
+	Scan(dest ...any) error
+}
+
+// Column is a column of a model whose values are T. The schema generator
+// emits one per column, e.g.
+//
+//	const UserEmail query.Column[string] = "email"
+type Column[T any] string
+
+func (c Column[T]) Name() string { return string(c) }
+
+func (c Column[T]) Eq(v T) Expression              { return Eq(string(c), v) }
+func (c Column[T]) Ne(v T) Expression              { return Ne(string(c), v) }
+func (c Column[T]) Gt(v T) Expression              { return Gt(string(c), v) }
+func (c Column[T]) Gte(v T) Expression             { return Gte(string(c), v) }
+func (c Column[T]) Lt(v T) Expression              { return Lt(string(c), v) }
+func (c Column[T]) Lte(v T) Expression             { return Lte(string(c), v) }
+func (c Column[T]) Like(pattern string) Expression { return Like(string(c), pattern) }
+func (c Column[T]) IsNull() Expression             { return IsNull(string(c)) }
+func (c Column[T]) IsNotNull() Expression          { return IsNotNull(string(c)) }
+
+func (c Column[T]) In(values ...T) Expression {
+	list := make([]any, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+
+	return in{column: string(c), values: list}
+}
+
+func (c Column[T]) Asc() Order  { return Order(string(c) + " ASC") }
+func (c Column[T]) Desc() Order { return Order(string(c) + " DESC") }
+
+// Order is an ordering of ORDER BY, e.g. "created_at DESC".
+type Order string
+
+// Table describes the model a Scope queries.
+type Table[T any] struct {
+	Name    string
+	Columns []string
+	Scan    func(rows Scanner) (T, error)
+	// NotFound is returned by First when no rows match. It defaults to
+	// sql.ErrNoRows.
+	NotFound error
+}
+
+// Scope is an immutable query of a model. Every method returns a new scope,
+// so scopes can be stored and reused.
+//
+//	users, err := fixtures.Users.
+//		Where(fixtures.UserEmail.Eq(email)).
+//		Order(fixtures.UserCreatedAt.Desc()).
+//		Limit(10).
+//		All(ctx, qr)
+type Scope[T any] struct {
+	table  *Table[T]
+	where  conditions
+	orders []string
+	limit  *int
+	offset *int
+}
+
+func NewScope[T any](t Table[T]) Scope[T] {
+	return Scope[T]{table: &t}
+}
+
+// Where adds the conditions with AND.
+func (s Scope[T]) Where(conds ...Expression) Scope[T] {
+	s.where = s.where[:len(s.where):len(s.where)]
+	s.where.and(conds)
+	return s
+}
+
+// Or joins the current conditions and conds with OR.
+func (s Scope[T]) Or(conds ...Expression) Scope[T] {
+	s.where = s.where[:len(s.where):len(s.where)]
+	s.where.or(conds)
+	return s
+}
+
+func (s Scope[T]) Order(orders ...Order) Scope[T] {
+	s.orders = s.orders[:len(s.orders):len(s.orders)]
+	for _, o := range orders {
+		s.orders = append(s.orders, string(o))
+	}
+
+	return s
+}
+
+func (s Scope[T]) Limit(n int) Scope[T] {
+	s.limit = &n
+	return s
+}
+
+func (s Scope[T]) Offset(n int) Scope[T] {
+	s.offset = &n
+	return s
+}
+
+// Select returns the query of the scope with the columns.
+func (s Scope[T]) Select(columns ...string) *SelectQuery {
+	q := Select(columns...).From(s.table.Name).Where(s.where...).OrderBy(s.orders...)
+	if s.limit != nil {
+		q.Limit(*s.limit)
+	}
+
+	if s.offset != nil {
+		q.Offset(*s.offset)
+	}
+
+	return q
+}
+
+// Query returns the query of All.
+func (s Scope[T]) Query() *SelectQuery {
+	return s.Select(s.table.Columns...)
+}
+
+// All returns the records in the scope. Like the other runners, it runs on the
+// transaction of ctx when qr is nil.
+func (s Scope[T]) All(ctx context.Context, qr orm.QueryRunner) ([]T, error) {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, goooerrors.Wrap(err)
+	}
+
+	rows, err := s.Query().Query(ctx, qr)
+	if err != nil {
+		return nil, goooerrors.Wrap(err)
+	}
+	defer rows.Close()
+
+	list := []T{}
+	for rows.Next() {
+		obj, err := s.table.Scan(rows)
+		if err != nil {
+			return nil, goooerrors.Wrap(err)
+		}
+		list = append(list, obj)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, goooerrors.Wrap(err)
+	}
+
+	// the hooks may query with qr, which must not have open rows in a
+	// transaction.
+	rows.Close()
+	for i := range list {
+		if err := afterFind(ctx, qr, &list[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// First returns the first record. Table.NotFound is returned when no rows
+// match.
+func (s Scope[T]) First(ctx context.Context, qr orm.QueryRunner) (T, error) {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		var zero T
+		return zero, goooerrors.Wrap(err)
+	}
+
+	obj, err := s.table.Scan(s.Limit(1).Query().QueryRow(ctx, qr))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) && s.table.NotFound != nil {
+			return obj, goooerrors.Wrap(s.table.NotFound)
+		}
+
+		return obj, goooerrors.Wrap(err)
+	}
+
+	if err := afterFind(ctx, qr, &obj); err != nil {
+		return obj, err
+	}
+
+	return obj, nil
+}
+
+// afterFind calls AfterFind when obj implements orm.AfterFinder.
+func afterFind(ctx context.Context, qr orm.QueryRunner, obj any) error {
+	if h, ok := obj.(orm.AfterFinder); ok {
+		return h.AfterFind(ctx, qr)
+	}
+
+	return nil
+}
+
+// Count counts the records matching the conditions. Order, limit and offset
+// are ignored.
+func (s Scope[T]) Count(ctx context.Context, qr orm.QueryRunner) (int64, error) {
+	var n int64
+	q := Select("count(*)").From(s.table.Name).Where(s.where...)
+	if err := q.QueryRow(ctx, qr).Scan(&n); err != nil {
+		return 0, goooerrors.Wrap(err)
+	}
+
+	return n, nil
+}
+
+func (s Scope[T]) Exists(ctx context.Context, qr orm.QueryRunner) (bool, error) {
+	var ok bool
+	sub := Select("1").From(s.table.Name).Where(s.where...)
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return false, goooerrors.Wrap(err)
+	}
+
+	q, args := Build(Raw("SELECT ?", Exists(sub)))
+	if err := qr.QueryRowContext(ctx, q, args...).Scan(&ok); err != nil {
+		return false, goooerrors.Wrap(err)
+	}
+
+	return ok, nil
+}
+
+// Pluck returns the values of column of the records in the scope.
+func Pluck[V any, T any](ctx context.Context, qr orm.QueryRunner, s Scope[T], column Column[V]) ([]V, error) {
+	rows, err := s.Select(column.Name()).Query(ctx, qr)
+	if err != nil {
+		return nil, goooerrors.Wrap(err)
+	}
+	defer rows.Close()
+
+	list := []V{}
+	for rows.Next() {
+		var v V
+		if err := rows.Scan(&v); err != nil {
+			return nil, goooerrors.Wrap(err)
+		}
+		list = append(list, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, goooerrors.Wrap(err)
+	}
+
+	return list, nil
+}
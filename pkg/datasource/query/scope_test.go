@@ -0,0 +1,93 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID    int
+	Email string
+}
+
+const (
+	userID        Column[int]       = "id"
+	userEmail     Column[string]    = "email"
+	userCreatedAt Column[time.Time] = "created_at"
+)
+
+var users = NewScope(Table[user]{
+	Name:    "users",
+	Columns: []string{"id", "email"},
+	Scan: func(rows Scanner) (user, error) {
+		u := user{}
+		err := rows.Scan(&u.ID, &u.Email)
+		return u, err
+	},
+})
+
+func TestScope(t *testing.T) {
+	active := users.Where(Eq("status", "active"))
+
+	tests := []struct {
+		name   string
+		query  Expression
+		expect string
+		args   []any
+	}{
+		{
+			name:   "all",
+			query:  users.Query(),
+			expect: "SELECT id, email FROM users",
+		},
+		{
+			name: "where, order and limit",
+			query: users.
+				Where(userEmail.Eq("a@example.com")).
+				Order(userCreatedAt.Desc(), userID.Asc()).
+				Limit(10).
+				Offset(5).
+				Query(),
+			expect: "SELECT id, email FROM users WHERE email = $1 ORDER BY created_at DESC, id ASC LIMIT $2 OFFSET $3",
+			args:   []any{"a@example.com", 10, 5},
+		},
+		{
+			name:   "derived scopes do not share conditions",
+			query:  active.Where(userID.In(1, 2)).Query(),
+			expect: "SELECT id, email FROM users WHERE status = $1 AND id IN ($2, $3)",
+			args:   []any{"active", 1, 2},
+		},
+		{
+			name:   "or",
+			query:  active.Or(userEmail.Like("%@example.com")).Query(),
+			expect: "SELECT id, email FROM users WHERE (status = $1 OR email LIKE $2)",
+			args:   []any{"active", "%@example.com"},
+		},
+		{
+			name:   "base scope",
+			query:  active.Query(),
+			expect: "SELECT id, email FROM users WHERE status = $1",
+			args:   []any{"active"},
+		},
+		{
+			name:   "pluck",
+			query:  active.Select(userEmail.Name()),
+			expect: "SELECT email FROM users WHERE status = $1",
+			args:   []any{"active"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, args := Build(test.query)
+			if s != test.expect {
+				t.Errorf("expect %s, got %s", test.expect, s)
+			}
+
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("expect %v, got %v", test.args, args)
+			}
+		})
+	}
+}
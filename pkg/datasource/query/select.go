@@ -0,0 +1,225 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/version-1/gooo/pkg/datasource/orm"
+)
+
+var _ Expression = &SelectQuery{}
+
+type join struct {
+	kind  string
+	table string
+	on    Expression
+}
+
+// SelectQuery builds a SELECT statement.
+//
+//	query.Select("id", "email").
+//		From("users").
+//		Where(query.Eq("email", email)).
+//		OrderBy("created_at DESC").
+//		Limit(10)
+type SelectQuery struct {
+	distinct bool
+	columns  []string
+	from     string
+	fromSub  *SelectQuery
+	joins    []join
+	where    conditions
+	groupBy  []string
+	having   conditions
+	orderBy  []string
+	limit    *int
+	offset   *int
+}
+
+// Select starts a query of the columns. No columns selects *.
+func Select(columns ...string) *SelectQuery {
+	return &SelectQuery{columns: columns}
+}
+
+func (q *SelectQuery) Distinct() *SelectQuery {
+	q.distinct = true
+	return q
+}
+
+func (q *SelectQuery) Columns(columns ...string) *SelectQuery {
+	q.columns = append(q.columns, columns...)
+	return q
+}
+
+func (q *SelectQuery) From(table string) *SelectQuery {
+	q.from = table
+	return q
+}
+
+// FromSubquery selects from sub named alias.
+func (q *SelectQuery) FromSubquery(sub *SelectQuery, alias string) *SelectQuery {
+	q.fromSub = sub
+	q.from = alias
+	return q
+}
+
+func (q *SelectQuery) Join(table string, on Expression) *SelectQuery {
+	q.joins = append(q.joins, join{kind: "JOIN", table: table, on: on})
+	return q
+}
+
+func (q *SelectQuery) LeftJoin(table string, on Expression) *SelectQuery {
+	q.joins = append(q.joins, join{kind: "LEFT JOIN", table: table, on: on})
+	return q
+}
+
+// Where adds the conditions with AND.
+func (q *SelectQuery) Where(conds ...Expression) *SelectQuery {
+	q.where.and(conds)
+	return q
+}
+
+// And is an alias of Where.
+func (q *SelectQuery) And(conds ...Expression) *SelectQuery {
+	return q.Where(conds...)
+}
+
+// Or joins the current conditions and conds with OR.
+func (q *SelectQuery) Or(conds ...Expression) *SelectQuery {
+	q.where.or(conds)
+	return q
+}
+
+func (q *SelectQuery) GroupBy(columns ...string) *SelectQuery {
+	q.groupBy = append(q.groupBy, columns...)
+	return q
+}
+
+func (q *SelectQuery) Having(conds ...Expression) *SelectQuery {
+	q.having.and(conds)
+	return q
+}
+
+// OrderBy adds the orderings, e.g. "created_at DESC".
+func (q *SelectQuery) OrderBy(orders ...string) *SelectQuery {
+	q.orderBy = append(q.orderBy, orders...)
+	return q
+}
+
+func (q *SelectQuery) Limit(n int) *SelectQuery {
+	q.limit = &n
+	return q
+}
+
+func (q *SelectQuery) Offset(n int) *SelectQuery {
+	q.offset = &n
+	return q
+}
+
+// WriteSQL writes the statement without parentheses, so that it can be
+// embedded as a subquery by In, Exists and Raw("(?)", q).
+func (q *SelectQuery) WriteSQL(b *Buffer) {
+	b.WriteString("SELECT ")
+	if q.distinct {
+		b.WriteString("DISTINCT ")
+	}
+
+	if len(q.columns) == 0 {
+		b.WriteString("*")
+	} else {
+		b.WriteString(strings.Join(q.columns, ", "))
+	}
+
+	if q.fromSub != nil {
+		b.WriteString(" FROM (")
+		q.fromSub.WriteSQL(b)
+		b.WriteString(") AS " + q.from)
+	} else if q.from != "" {
+		b.WriteString(" FROM " + q.from)
+	}
+
+	for _, j := range q.joins {
+		b.WriteString(" " + j.kind + " " + j.table)
+		if j.on != nil {
+			b.WriteString(" ON ")
+			j.on.WriteSQL(b)
+		}
+	}
+
+	q.where.write(b, "WHERE")
+
+	if len(q.groupBy) > 0 {
+		b.WriteString(" GROUP BY " + strings.Join(q.groupBy, ", "))
+	}
+
+	q.having.write(b, "HAVING")
+
+	if len(q.orderBy) > 0 {
+		b.WriteString(" ORDER BY " + strings.Join(q.orderBy, ", "))
+	}
+
+	if q.limit != nil {
+		b.WriteString(" LIMIT ")
+		b.Arg(*q.limit)
+	}
+
+	if q.offset != nil {
+		b.WriteString(" OFFSET ")
+		b.Arg(*q.offset)
+	}
+}
+
+func (q *SelectQuery) Build() (string, []any) {
+	return Build(q)
+}
+
+func (q *SelectQuery) Query(ctx context.Context, qr orm.QueryRunner) (*sql.Rows, error) {
+	s, args := q.Build()
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.QueryContext(ctx, s, args...)
+}
+
+func (q *SelectQuery) QueryRow(ctx context.Context, qr orm.QueryRunner) *Row {
+	s, args := q.Build()
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	return &Row{row: qr.QueryRowContext(ctx, s, args...)}
+}
+
+// conditions are joined with AND.
+type conditions []Expression
+
+func (c *conditions) and(conds []Expression) {
+	*c = append(*c, compact(conds)...)
+}
+
+func (c *conditions) or(conds []Expression) {
+	conds = compact(conds)
+	if len(conds) == 0 {
+		return
+	}
+
+	if len(*c) == 0 {
+		*c = conds
+		return
+	}
+
+	*c = conditions{Or(And(*c...), And(conds...))}
+}
+
+func (c conditions) write(b *Buffer, keyword string) {
+	if len(c) == 0 {
+		return
+	}
+
+	b.WriteString(" " + keyword + " ")
+	writeJoined(b, "AND", c)
+}
@@ -0,0 +1,218 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/version-1/gooo/pkg/datasource/orm"
+)
+
+var _ Expression = &UpdateQuery{}
+var _ Expression = &DeleteQuery{}
+
+// UpdateQuery builds an UPDATE statement.
+//
+//	query.Update("users").
+//		Set("email", email).
+//		Set("updated_at", query.Raw("NOW()")).
+//		Where(query.Eq("id", id))
+//
+// Build fails without a where clause unless All is called.
+type UpdateQuery struct {
+	table     string
+	sets      []assignment
+	from      string
+	where     conditions
+	all       bool
+	returning []string
+}
+
+func Update(table string) *UpdateQuery {
+	return &UpdateQuery{table: table}
+}
+
+// Set assigns v to column. v may be an Expression.
+func (q *UpdateQuery) Set(column string, v any) *UpdateQuery {
+	q.sets = append(q.sets, assignment{column: column, value: v})
+	return q
+}
+
+func (q *UpdateQuery) From(table string) *UpdateQuery {
+	q.from = table
+	return q
+}
+
+func (q *UpdateQuery) Where(conds ...Expression) *UpdateQuery {
+	q.where.and(conds)
+	return q
+}
+
+func (q *UpdateQuery) And(conds ...Expression) *UpdateQuery {
+	return q.Where(conds...)
+}
+
+func (q *UpdateQuery) Or(conds ...Expression) *UpdateQuery {
+	q.where.or(conds)
+	return q
+}
+
+// All allows the statement to update every row when no where clause is given.
+func (q *UpdateQuery) All() *UpdateQuery {
+	q.all = true
+	return q
+}
+
+func (q *UpdateQuery) Returning(columns ...string) *UpdateQuery {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+func (q *UpdateQuery) WriteSQL(b *Buffer) {
+	b.WriteString("UPDATE " + q.table)
+	writeSet(b, q.sets)
+	if q.from != "" {
+		b.WriteString(" FROM " + q.from)
+	}
+	q.where.write(b, "WHERE")
+	writeReturning(b, q.returning)
+}
+
+// Build renders the statement. It fails when no column is set, or when there
+// is no where clause and All is not called.
+func (q *UpdateQuery) Build() (string, []any, error) {
+	if len(q.sets) == 0 {
+		return "", nil, ErrSetRequired
+	}
+
+	if len(q.where) == 0 && !q.all {
+		return "", nil, ErrWhereRequired
+	}
+
+	s, args := Build(q)
+	return s, args, nil
+}
+
+func (q *UpdateQuery) Exec(ctx context.Context, qr orm.QueryRunner) (sql.Result, error) {
+	s, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.ExecContext(ctx, s, args...)
+}
+
+func (q *UpdateQuery) Query(ctx context.Context, qr orm.QueryRunner) (*sql.Rows, error) {
+	s, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.QueryContext(ctx, s, args...)
+}
+
+func (q *UpdateQuery) QueryRow(ctx context.Context, qr orm.QueryRunner) *Row {
+	s, args, err := q.Build()
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	return &Row{row: qr.QueryRowContext(ctx, s, args...)}
+}
+
+// DeleteQuery builds a DELETE statement. Build fails without a where clause
+// unless All is called.
+type DeleteQuery struct {
+	table     string
+	where     conditions
+	all       bool
+	returning []string
+}
+
+func DeleteFrom(table string) *DeleteQuery {
+	return &DeleteQuery{table: table}
+}
+
+func (q *DeleteQuery) Where(conds ...Expression) *DeleteQuery {
+	q.where.and(conds)
+	return q
+}
+
+func (q *DeleteQuery) And(conds ...Expression) *DeleteQuery {
+	return q.Where(conds...)
+}
+
+func (q *DeleteQuery) Or(conds ...Expression) *DeleteQuery {
+	q.where.or(conds)
+	return q
+}
+
+// All allows the statement to delete every row when no where clause is given.
+func (q *DeleteQuery) All() *DeleteQuery {
+	q.all = true
+	return q
+}
+
+func (q *DeleteQuery) Returning(columns ...string) *DeleteQuery {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+func (q *DeleteQuery) WriteSQL(b *Buffer) {
+	b.WriteString("DELETE FROM " + q.table)
+	q.where.write(b, "WHERE")
+	writeReturning(b, q.returning)
+}
+
+// Build renders the statement. It fails when there is no where clause and
+// All is not called.
+func (q *DeleteQuery) Build() (string, []any, error) {
+	if len(q.where) == 0 && !q.all {
+		return "", nil, ErrWhereRequired
+	}
+
+	s, args := Build(q)
+	return s, args, nil
+}
+
+func (q *DeleteQuery) Exec(ctx context.Context, qr orm.QueryRunner) (sql.Result, error) {
+	s, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.ExecContext(ctx, s, args...)
+}
+
+func (q *DeleteQuery) Query(ctx context.Context, qr orm.QueryRunner) (*sql.Rows, error) {
+	s, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err = orm.Runner(ctx, qr)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.QueryContext(ctx, s, args...)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
 	"github.com/version-1/gooo/pkg/logger"
 )
 
@@ -19,8 +21,16 @@ type QueryRunner interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
+// Beginner begins a transaction. A Tx begins a nested transaction with a
+// savepoint, so functions opening their own transaction compose when called
+// within another one.
+type Beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
+
 type Tx interface {
 	QueryRunner
+	Beginner
 	Commit() error
 	Rollback() error
 }
@@ -38,34 +48,41 @@ func (d *DB) SetLogger(l logger.Logger) {
 	d.logger = &queryLoggerAdapter{l}
 }
 
+// QueryRow runs a query returning at most one row. The error of Scan is not
+// translated, as *sql.Row can't be wrapped. Pass it to ormerrors.Translate.
 func (d *DB) QueryRow(query string, args ...interface{}) *sql.Row {
-	d.logger.Log(query, args)
+	d.logger.Log(query, args...)
 	return d.executor.QueryRow(query, args...)
 }
 
 func (d *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	d.logger.Log(query, args)
-	return d.executor.Query(query, args...)
+	d.logger.Log(query, args...)
+	rows, err := d.executor.Query(query, args...)
+	return rows, ormerrors.Translate(err)
 }
 
 func (d *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	d.logger.Log(query, args)
-	return d.executor.Exec(query, args...)
+	d.logger.Log(query, args...)
+	res, err := d.executor.Exec(query, args...)
+	return res, ormerrors.Translate(err)
 }
 
 func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	d.logger.Log(query, args)
-	return d.executor.QueryContext(ctx, query, args...)
+	d.logger.LogContext(ctx, query, args...)
+	rows, err := d.executor.QueryContext(ctx, query, args...)
+	return rows, ormerrors.Translate(err)
 }
 
+// QueryRowContext is QueryRow with ctx.
 func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
-	d.logger.Log(query, args)
+	d.logger.LogContext(ctx, query, args...)
 	return d.executor.QueryRowContext(ctx, query, args...)
 }
 
 func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
-	d.logger.Log(query, args)
-	return d.executor.ExecContext(ctx, query, args...)
+	d.logger.LogContext(ctx, query, args...)
+	res, err := d.executor.ExecContext(ctx, query, args...)
+	return res, ormerrors.Translate(err)
 }
 
 func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
@@ -95,9 +112,45 @@ func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
 type txManager struct {
 	id uuid.UUID
 	*DB
+	// savepoint is the savepoint of a nested transaction.
+	savepoint string
+	// done is set once the transaction is committed or rolled back, so that the
+	// rollback deferred after a commit doesn't run.
+	done bool
+}
+
+// BeginTx begins a nested transaction with a savepoint. The options of the
+// outermost transaction apply, so opts must be nil or the default.
+func (d *txManager) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	if opts != nil && (opts.Isolation != sql.LevelDefault || opts.ReadOnly) {
+		return nil, fmt.Errorf("options of a nested transaction are not supported: %+v", *opts)
+	}
+
+	id := uuid.New()
+	savepoint := "sp_" + strings.ReplaceAll(id.String(), "-", "")
+	if _, err := d.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return nil, err
+	}
+
+	d.logger.Printf("begin nested tx: %s in %s", id, d.id)
+	return &txManager{id: id, DB: d.DB, savepoint: savepoint}, nil
 }
 
+// Commit commits the transaction, or releases the savepoint of a nested one.
+// It returns sql.ErrTxDone when the transaction is already done.
 func (d *txManager) Commit() error {
+	if d.done {
+		return sql.ErrTxDone
+	}
+
+	if d.savepoint != "" {
+		d.logger.Printf("release: %s", d.id)
+		_, err := d.Exec("RELEASE SAVEPOINT " + d.savepoint)
+		d.done = err == nil
+		return err
+	}
+
+	d.done = true
 	switch v := d.executor.(type) {
 	case *sql.Tx:
 		d.logger.Printf("commit: %s", d.id)
@@ -110,7 +163,22 @@ func (d *txManager) Commit() error {
 	}
 }
 
+// Rollback rolls the transaction back, or rolls back to the savepoint of a
+// nested one. It returns sql.ErrTxDone when the transaction is already done,
+// so it is safe to defer.
 func (d *txManager) Rollback() error {
+	if d.done {
+		return sql.ErrTxDone
+	}
+
+	if d.savepoint != "" {
+		d.logger.Printf("rollback to savepoint: %s", d.id)
+		_, err := d.Exec("ROLLBACK TO SAVEPOINT " + d.savepoint)
+		d.done = err == nil
+		return err
+	}
+
+	d.done = true
 	switch v := d.executor.(type) {
 	case *sql.Tx:
 		d.logger.Printf("rollback: %s", d.id)
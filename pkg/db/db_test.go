@@ -0,0 +1,64 @@
+package db
+
+import (
+	gocontext "context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// execConn records the statements executed on it.
+type execConn struct {
+	*fakeConn
+	execs []string
+}
+
+func (c *execConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	c.execs = append(c.execs, query)
+	return nil, nil
+}
+
+func (c *execConn) ExecContext(ctx gocontext.Context, query string, args ...any) (sql.Result, error) {
+	c.execs = append(c.execs, query)
+	return nil, nil
+}
+
+func TestNestedTxCommitThenRollback(t *testing.T) {
+	conn := &execConn{fakeConn: &fakeConn{queries: &[]string{}}}
+	outer := &txManager{DB: New(conn)}
+
+	err := func() error {
+		tx, err := outer.BeginTx(gocontext.Background(), nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		return tx.Commit()
+	}()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conn.execs) != 2 || !strings.HasPrefix(conn.execs[0], "SAVEPOINT ") || !strings.HasPrefix(conn.execs[1], "RELEASE SAVEPOINT ") {
+		t.Fatalf("expected the savepoint to be released only, got %v", conn.execs)
+	}
+
+	tx, err := outer.BeginTx(gocontext.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected sql.ErrTxDone, got %v", err)
+	}
+
+	if err := tx.Rollback(); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected sql.ErrTxDone, got %v", err)
+	}
+}
@@ -1,14 +1,18 @@
 package db
 
 import (
+	gocontext "context"
 	"fmt"
 	"strings"
 
+	"github.com/version-1/gooo/pkg/context"
 	"github.com/version-1/gooo/pkg/logger"
 )
 
 type QueryLogger interface {
 	Log(query string, args ...any)
+	// LogContext is the same as Log but tags the entry with the request id of ctx.
+	LogContext(ctx gocontext.Context, query string, args ...any)
 	Println(v ...any)
 	Printf(format string, v ...any)
 }
@@ -18,6 +22,14 @@ type queryLoggerAdapter struct {
 }
 
 func (q *queryLoggerAdapter) Log(query string, args ...any) {
+	q.log(q.logger, query, args...)
+}
+
+func (q *queryLoggerAdapter) LogContext(ctx gocontext.Context, query string, args ...any) {
+	q.log(context.ScopedLogger(ctx, q.logger), query, args...)
+}
+
+func (q *queryLoggerAdapter) log(l logger.Logger, query string, args ...any) {
 	_args := []string{}
 	_query := query
 	for i, arg := range args {
@@ -25,7 +37,7 @@ func (q *queryLoggerAdapter) Log(query string, args ...any) {
 		_query = strings.Replace(_query, fmt.Sprintf("$%d", i+1), humanize(arg), 1)
 	}
 
-	q.logger.Infof("executing query: %s args: %s", _query, strings.Join(_args, ", "))
+	l.Infof("executing query: %s args: %s", _query, strings.Join(_args, ", "))
 }
 
 func (q *queryLoggerAdapter) Println(v ...any) {
@@ -99,6 +111,4 @@ func humanize(v any) string {
 	default:
 		return fmt.Sprintf("%v", v)
 	}
-
-	return ""
 }
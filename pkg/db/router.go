@@ -0,0 +1,154 @@
+package db
+
+import (
+	gocontext "context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/version-1/gooo/pkg/context"
+	"github.com/version-1/gooo/pkg/logger"
+)
+
+var _ QueryRunner = &Router{}
+var _ Beginner = &Router{}
+
+// Pinger is implemented by connections that can check they are alive, e.g.
+// *sql.DB.
+type Pinger interface {
+	PingContext(ctx gocontext.Context) error
+}
+
+// Router sends reads to the read replicas in turn and writes and transactions
+// to the primary. Reads go to the primary as well when ctx is marked with
+// context.WithPrimary, e.g. to read your own writes, or when no replica is
+// healthy.
+//
+//	r := db.NewRouter(primary, replica1, replica2)
+//	go r.WatchHealth(ctx, 10*time.Second)
+type Router struct {
+	primary  *DB
+	replicas []*replica
+	next     atomic.Uint64
+	logger   QueryLogger
+}
+
+type replica struct {
+	*DB
+	healthy atomic.Bool
+}
+
+func NewRouter(primary QueryRunner, replicas ...QueryRunner) *Router {
+	r := &Router{primary: New(primary), logger: defaultLogger}
+	for _, conn := range replicas {
+		rep := &replica{DB: New(conn)}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+
+	return r
+}
+
+func (r *Router) SetLogger(l logger.Logger) {
+	r.logger = &queryLoggerAdapter{l}
+	r.primary.SetLogger(l)
+	for _, rep := range r.replicas {
+		rep.SetLogger(l)
+	}
+}
+
+// Primary returns the connection to the primary.
+func (r *Router) Primary() *DB {
+	return r.primary
+}
+
+// reader returns the next healthy replica, or the primary when ctx requires
+// it or no replica is healthy.
+func (r *Router) reader(ctx gocontext.Context) *DB {
+	if len(r.replicas) == 0 || context.Primary(ctx) {
+		return r.primary
+	}
+
+	start := r.next.Add(1) - 1
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep.DB
+		}
+	}
+
+	return r.primary
+}
+
+func (r *Router) QueryRow(query string, args ...interface{}) *sql.Row {
+	return r.reader(gocontext.Background()).QueryRow(query, args...)
+}
+
+func (r *Router) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.reader(gocontext.Background()).Query(query, args...)
+}
+
+func (r *Router) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.Exec(query, args...)
+}
+
+func (r *Router) QueryContext(ctx gocontext.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.reader(ctx).QueryContext(ctx, query, args...)
+}
+
+func (r *Router) QueryRowContext(ctx gocontext.Context, query string, args ...any) *sql.Row {
+	return r.reader(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (r *Router) ExecContext(ctx gocontext.Context, query string, args ...any) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+// BeginTx begins a transaction on the primary, so that every query of the
+// transaction reads its own writes.
+func (r *Router) BeginTx(ctx gocontext.Context, opts *sql.TxOptions) (Tx, error) {
+	return r.primary.BeginTx(ctx, opts)
+}
+
+// CheckHealth checks every replica once. A replica failing the check gets no
+// reads until it passes again. Replicas implementing Pinger are pinged,
+// others run SELECT 1.
+func (r *Router) CheckHealth(ctx gocontext.Context) {
+	for i, rep := range r.replicas {
+		err := ping(ctx, rep.executor)
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) == healthy {
+			continue
+		}
+
+		if healthy {
+			r.logger.Printf("replica %d is healthy again", i)
+		} else {
+			r.logger.Printf("replica %d is unhealthy: %s", i, err)
+		}
+	}
+}
+
+// WatchHealth checks the replicas every interval until ctx is done.
+func (r *Router) WatchHealth(ctx gocontext.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			r.CheckHealth(ctx)
+		}
+	}
+}
+
+func ping(ctx gocontext.Context, conn QueryRunner) error {
+	if p, ok := conn.(Pinger); ok {
+		return p.PingContext(ctx)
+	}
+
+	var n int
+	return conn.QueryRowContext(ctx, "SELECT 1").Scan(&n)
+}
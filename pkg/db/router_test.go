@@ -0,0 +1,128 @@
+package db
+
+import (
+	gocontext "context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/version-1/gooo/pkg/context"
+)
+
+// fakeConn records the queries it receives instead of running them.
+type fakeConn struct {
+	name    string
+	queries *[]string
+	down    bool
+}
+
+func (c *fakeConn) record() {
+	*c.queries = append(*c.queries, c.name)
+}
+
+func (c *fakeConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	c.record()
+	return nil
+}
+
+func (c *fakeConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	c.record()
+	return nil, nil
+}
+
+func (c *fakeConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	c.record()
+	return nil, nil
+}
+
+func (c *fakeConn) QueryContext(ctx gocontext.Context, query string, args ...any) (*sql.Rows, error) {
+	c.record()
+	return nil, nil
+}
+
+func (c *fakeConn) QueryRowContext(ctx gocontext.Context, query string, args ...any) *sql.Row {
+	c.record()
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx gocontext.Context, query string, args ...any) (sql.Result, error) {
+	c.record()
+	return nil, nil
+}
+
+func (c *fakeConn) PingContext(ctx gocontext.Context) error {
+	if c.down {
+		return errors.New("connection refused")
+	}
+
+	return nil
+}
+
+func TestRouter(t *testing.T) {
+	queries := []string{}
+	primary := &fakeConn{name: "primary", queries: &queries}
+	replica1 := &fakeConn{name: "replica1", queries: &queries}
+	replica2 := &fakeConn{name: "replica2", queries: &queries}
+	r := NewRouter(primary, replica1, replica2)
+	ctx := gocontext.Background()
+
+	run := func(fn func()) []string {
+		queries = []string{}
+		fn()
+		return queries
+	}
+
+	expect := func(name string, got []string, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("%s: expect %v, got %v", name, want, got)
+			return
+		}
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: expect %v, got %v", name, want, got)
+				return
+			}
+		}
+	}
+
+	expect("reads in turn", run(func() {
+		r.QueryContext(ctx, "SELECT 1")
+		r.QueryRowContext(ctx, "SELECT 1")
+		r.Query("SELECT 1")
+		r.QueryRow("SELECT 1")
+	}), "replica1", "replica2", "replica1", "replica2")
+
+	expect("writes", run(func() {
+		r.ExecContext(ctx, "UPDATE users SET name = 'gooo'")
+		r.Exec("UPDATE users SET name = 'gooo'")
+	}), "primary", "primary")
+
+	expect("forced primary", run(func() {
+		r.QueryContext(context.WithPrimary(ctx), "SELECT 1")
+	}), "primary")
+
+	replica1.down = true
+	r.CheckHealth(ctx)
+	expect("unhealthy replica", run(func() {
+		r.QueryContext(ctx, "SELECT 1")
+		r.QueryContext(ctx, "SELECT 1")
+	}), "replica2", "replica2")
+
+	replica2.down = true
+	r.CheckHealth(ctx)
+	expect("no healthy replica", run(func() {
+		r.QueryContext(ctx, "SELECT 1")
+	}), "primary")
+
+	replica1.down = false
+	r.CheckHealth(ctx)
+	expect("recovered replica", run(func() {
+		r.QueryContext(ctx, "SELECT 1")
+	}), "replica1")
+
+	if _, err := r.BeginTx(ctx, nil); err == nil {
+		t.Error("expect the transaction to begin on the primary, which does not support transactions")
+	}
+}
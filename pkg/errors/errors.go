@@ -40,6 +40,12 @@ func (e Error) StackTrace() string {
 	return fmt.Sprintf("%+v", e.stack)
 }
 
+// Unwrap returns the wrapped error, so that errors.Is and errors.As see
+// through Wrap.
+func (e Error) Unwrap() error {
+	return e.err
+}
+
 func (e Error) Error() string {
 	return fmt.Sprintf("pkg/errors : %s", e.err)
 }
@@ -0,0 +1,15 @@
+package errors
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWrapUnwrap(t *testing.T) {
+	err := Wrap(fmt.Errorf("query: %w", sql.ErrNoRows))
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expect %v to wrap sql.ErrNoRows", err)
+	}
+}
@@ -2,12 +2,15 @@ package client
 
 import (
 	"bytes"
-	"context"
+	gocontext "context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/version-1/gooo/pkg/context"
+	"github.com/version-1/gooo/pkg/logger"
 )
 
 const pkgName = "pkg/http/client"
@@ -19,6 +22,8 @@ type Config struct {
 	Headers map[string]string
 }
 
+var _ Logger = logger.Logger(nil)
+
 type Logger interface {
 	Infof(string, ...any)
 	Debugf(string, ...any)
@@ -128,14 +133,18 @@ type Doer interface {
 	UnmarshalBody(*http.Response, any) error
 }
 
-func Do[K, V any](ctx context.Context, d Doer, body *K, response *V) error {
+// Do sends body as JSON and decodes the response into response. The request
+// id of ctx is forwarded in the X-Request-ID header and added to the logs.
+func Do[K, V any](ctx gocontext.Context, d Doer, body *K, response *V) error {
 	b, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
 
-	d.Logger().Debugf("%s: request uri: %s, method: %s", pkgName, d.RequestURI(), d.Method())
-	d.Logger().Debugf("%s: request body: %s", pkgName, string(b))
+	id := context.RequestID(ctx)
+	l := logger.WithRequestID(d.Logger(), id)
+	l.Debugf("%s: request uri: %s, method: %s", pkgName, d.RequestURI(), d.Method())
+	l.Debugf("%s: request body: %s", pkgName, string(b))
 
 	var reqBody io.Reader = nil
 	if body != nil && (d.Method() == http.MethodPost || d.Method() == http.MethodPut || d.Method() == http.MethodPatch) {
@@ -155,12 +164,15 @@ func Do[K, V any](ctx context.Context, d Doer, body *K, response *V) error {
 	for k, v := range d.Header() {
 		req.Header.Set(k, v)
 	}
+	if id != "" && req.Header.Get(context.RequestIDHeader) == "" {
+		req.Header.Set(context.RequestIDHeader, id)
+	}
 
 	res, err := d.Do(req)
 	if err != nil {
 		return err
 	}
-	d.Logger().Debugf("%s: response status: %s", pkgName, res.Status)
+	l.Debugf("%s: response status: %s", pkgName, res.Status)
 
 	if err := d.UnmarshalBody(res, response); err != nil {
 		return err
@@ -0,0 +1,479 @@
+package request
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
+	"github.com/version-1/gooo/pkg/datasource/orm/validator"
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+	"github.com/version-1/gooo/pkg/presenter/jsonapi"
+)
+
+// MaxMultipartMemory is the memory limit passed to ParseMultipartForm.
+var MaxMultipartMemory int64 = 32 << 20
+
+const (
+	tagJSON     = "json"
+	tagForm     = "form"
+	tagQuery    = "query"
+	tagParam    = "param"
+	tagHeader   = "header"
+	tagValidate = "validate"
+)
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Bind fills obj from the body (JSON, form or multipart), query, path params
+// and headers of the request, then validates it with the validate tag.
+// Fields tagged param, query or header are bound only from the path params,
+// query or headers, and never from the body.
+//
+//	type CreateUser struct {
+//		OrgID int                   `param:"org_id"`
+//		Token string                `header:"X-Token" validate:"required"`
+//		Email string                `json:"email" form:"email" validate:"required,email"`
+//		Role  string                `json:"role" validate:"oneof=admin|member"`
+//		Icon  *multipart.FileHeader `form:"icon"`
+//	}
+//
+// Rules are separated by commas: required, which rejects zero values, email,
+// oneof=a|b and format=<regexp>, which must be the last rule. The returned
+// error is jsonapi.Errors with 400 for malformed input and 422 for invalid
+// fields, so it can be rendered with response.ErrorWith. An invalid validate
+// tag is reported as a plain error.
+func Bind(r *Request, obj any) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return goooerrors.Wrap(ormerrors.NewNotStructError(obj))
+	}
+
+	rules, err := structRules(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	fields := bindFields(rv.Elem(), rules)
+	if err := bindBody(r, rv.Elem(), fields); err != nil {
+		return err
+	}
+
+	errs := jsonapi.Errors{}
+	for _, f := range fields {
+		if err := bindField(r, f); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for _, f := range fields {
+		errs = append(errs, validateField(f)...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+type bindingField struct {
+	value reflect.Value
+	field reflect.StructField
+	rules []rule
+}
+
+func (f bindingField) tag(name string) string {
+	v, _, _ := strings.Cut(f.field.Tag.Get(name), ",")
+	if v == "-" {
+		return ""
+	}
+
+	return v
+}
+
+// fromBody reports whether the body may set f.
+func (f bindingField) fromBody() bool {
+	return f.tag(tagParam) == "" && f.tag(tagQuery) == "" && f.tag(tagHeader) == ""
+}
+
+func (f bindingField) source() *jsonapi.ErrorSource {
+	switch {
+	case f.tag(tagParam) != "":
+		return &jsonapi.ErrorSource{Parameter: f.tag(tagParam)}
+	case f.tag(tagQuery) != "":
+		return &jsonapi.ErrorSource{Parameter: f.tag(tagQuery)}
+	case f.tag(tagHeader) != "":
+		return &jsonapi.ErrorSource{Header: f.tag(tagHeader)}
+	case f.tag(tagJSON) != "":
+		return &jsonapi.ErrorSource{Pointer: "/" + f.tag(tagJSON)}
+	case f.tag(tagForm) != "":
+		return &jsonapi.ErrorSource{Parameter: f.tag(tagForm)}
+	default:
+		return &jsonapi.ErrorSource{Pointer: "/" + f.field.Name}
+	}
+}
+
+func (f bindingField) key() string {
+	s := f.source()
+	switch {
+	case s.Parameter != "":
+		return s.Parameter
+	case s.Header != "":
+		return s.Header
+	default:
+		return strings.TrimPrefix(s.Pointer, "/")
+	}
+}
+
+func bindFields(v reflect.Value, rules [][]rule) []bindingField {
+	fields := []bindingField{}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		fields = append(fields, bindingField{value: v.Field(i), field: f, rules: rules[i]})
+	}
+
+	return fields
+}
+
+func bindBody(r *Request, v reflect.Value, fields []bindingField) error {
+	if r.Request.Body == nil || r.Request.Body == http.NoBody {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Request.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		if err := r.Request.ParseForm(); err != nil {
+			return jsonapi.Errors{jsonapi.NewBadRequest(err)}
+		}
+
+		return bindForm(fields, r.Request.PostForm, nil)
+	case "multipart/form-data":
+		if err := r.Request.ParseMultipartForm(MaxMultipartMemory); err != nil {
+			return jsonapi.Errors{jsonapi.NewBadRequest(err)}
+		}
+
+		return bindForm(fields, r.Request.MultipartForm.Value, r.Request.MultipartForm.File)
+	default:
+		b, err := io.ReadAll(r.Request.Body)
+		if err != nil {
+			return jsonapi.Errors{jsonapi.NewBadRequest(err)}
+		}
+		defer r.Request.Body.Close()
+
+		if len(b) == 0 {
+			return nil
+		}
+
+		// the body is decoded into a copy, of which only the fields bound from
+		// the body are taken.
+		scratch := reflect.New(v.Type()).Elem()
+		scratch.Set(v)
+		for _, f := range fields {
+			if !f.fromBody() {
+				scratch.FieldByIndex(f.field.Index).SetZero()
+			}
+		}
+
+		if err := json.Unmarshal(b, scratch.Addr().Interface()); err != nil {
+			e := jsonapi.NewBadRequest(err)
+			if ute, ok := err.(*json.UnmarshalTypeError); ok && ute.Field != "" {
+				e.Source = &jsonapi.ErrorSource{Pointer: "/" + strings.ReplaceAll(ute.Field, ".", "/")}
+			}
+
+			return jsonapi.Errors{e}
+		}
+
+		for _, f := range fields {
+			if f.fromBody() {
+				f.value.Set(scratch.FieldByIndex(f.field.Index))
+			}
+		}
+
+		return nil
+	}
+}
+
+func bindForm(fields []bindingField, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	errs := jsonapi.Errors{}
+	for _, f := range fields {
+		name := f.tag(tagForm)
+		if name == "" || !f.fromBody() {
+			continue
+		}
+
+		if fhs, ok := files[name]; ok && len(fhs) > 0 {
+			switch {
+			case f.value.Type() == fileHeaderType:
+				f.value.Set(reflect.ValueOf(fhs[0]))
+			case f.value.Type() == reflect.SliceOf(fileHeaderType):
+				f.value.Set(reflect.ValueOf(fhs))
+			}
+			continue
+		}
+
+		if vs, ok := values[name]; ok {
+			if err := setValues(f.value, vs); err != nil {
+				errs = append(errs, bindError(f, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func bindField(r *Request, f bindingField) *jsonapi.Error {
+	var values []string
+	switch {
+	case f.tag(tagParam) != "":
+		if r.Handler == nil {
+			return nil
+		}
+
+		v, ok := r.Param(f.tag(tagParam))
+		if !ok {
+			return nil
+		}
+		values = []string{v}
+	case f.tag(tagQuery) != "":
+		vs, ok := r.Request.URL.Query()[f.tag(tagQuery)]
+		if !ok {
+			return nil
+		}
+		values = vs
+	case f.tag(tagHeader) != "":
+		vs := r.Request.Header.Values(f.tag(tagHeader))
+		if len(vs) == 0 {
+			return nil
+		}
+		values = vs
+	default:
+		return nil
+	}
+
+	if err := setValues(f.value, values); err != nil {
+		e := bindError(f, err)
+		return &e
+	}
+
+	return nil
+}
+
+func bindError(f bindingField, err error) jsonapi.Error {
+	e := jsonapi.NewBadRequest(err)
+	e.Detail = fmt.Sprintf("%s is invalid: %s", f.key(), err)
+	e.Source = f.source()
+
+	return e
+}
+
+func setValues(v reflect.Value, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && !v.Type().Implements(textUnmarshalerType) {
+		list := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := setValue(list.Index(i), s); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+
+		return nil
+	}
+
+	return setValue(v, values[0])
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(ptr)
+
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+type rule struct {
+	name     string
+	validate validator.Validator
+}
+
+func parseRules(tag string) ([]rule, error) {
+	rules := []rule{}
+	for tag != "" {
+		var seg string
+		if strings.HasPrefix(tag, "format=") {
+			seg, tag = tag, ""
+		} else {
+			seg, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, arg, _ := strings.Cut(strings.TrimSpace(seg), "=")
+		switch name {
+		case "required":
+			rules = append(rules, rule{name: name, validate: validator.NotEmpty})
+		case "email":
+			rules = append(rules, rule{name: name, validate: validator.Email})
+		case "oneof":
+			values := []fmt.Stringer{}
+			for _, s := range strings.Split(arg, "|") {
+				values = append(values, stringValue(s))
+			}
+			rules = append(rules, rule{name: name, validate: validator.OneOf(values)})
+		case "format":
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule{name: name, validate: validator.Format(re)})
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown validation rule %q", name)
+		}
+	}
+
+	return rules, nil
+}
+
+type cachedRules struct {
+	rules [][]rule
+	err   error
+}
+
+// rulesCache holds the rules of each struct type by field index, so that the
+// validate tags are parsed once.
+var rulesCache sync.Map
+
+func structRules(t reflect.Type) ([][]rule, error) {
+	if c, ok := rulesCache.Load(t); ok {
+		return c.(cachedRules).rules, c.(cachedRules).err
+	}
+
+	c := cachedRules{rules: make([][]rule, t.NumField())}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(tagValidate)
+		if !f.IsExported() || tag == "" {
+			continue
+		}
+
+		rules, err := parseRules(tag)
+		if err != nil {
+			c = cachedRules{err: goooerrors.Errorf("invalid validate tag on %s.%s: %s", t.Name(), f.Name, err)}
+			break
+		}
+		c.rules[i] = rules
+	}
+
+	rulesCache.Store(t, c)
+	return c.rules, c.err
+}
+
+type stringValue string
+
+func (s stringValue) String() string {
+	return string(s)
+}
+
+func validateField(f bindingField) jsonapi.Errors {
+	if len(f.rules) == 0 {
+		return nil
+	}
+
+	value := f.value
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	errs := jsonapi.Errors{}
+	for _, rl := range f.rules {
+		if rl.name != "required" && validator.IsEmpty(value.Interface()) {
+			continue
+		}
+
+		if verr := rl.validate(f.key())(value.Interface()); verr != nil {
+			e := jsonapi.NewUnprocessableEntity(verr)
+			e.Code = rl.name
+			e.Title = "Invalid Attribute"
+			e.Detail = fmt.Sprintf("%s %s", verr.Key(), verr.Error())
+			e.Source = f.source()
+			errs = append(errs, e)
+		}
+	}
+
+	return errs
+}
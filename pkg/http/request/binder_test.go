@@ -0,0 +1,180 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/version-1/gooo/pkg/presenter/jsonapi"
+)
+
+type paramStub map[string]string
+
+func (p paramStub) Param(_ string, key string) (string, bool) {
+	v, ok := p[key]
+	return v, ok
+}
+
+func (p paramStub) ParamInt(_ string, key string) (int, bool) {
+	return 0, false
+}
+
+type createUser struct {
+	OrgID  int      `param:"org_id"`
+	Token  string   `header:"X-Token" validate:"required"`
+	Email  string   `json:"email" form:"email" validate:"required,email"`
+	Role   string   `json:"role" form:"role" validate:"oneof=admin|member"`
+	Age    *int     `json:"age"`
+	Tags   []string `query:"tag"`
+	Dryrun bool     `query:"dry_run"`
+}
+
+func newBindRequest(body, contentType string, params map[string]string) *Request {
+	req := httptest.NewRequest(http.MethodPost, "/orgs/1/users?tag=a&tag=b&dry_run=true", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Token", "secret")
+
+	return &Request{Request: req, Handler: paramStub(params)}
+}
+
+func TestBind(t *testing.T) {
+	age := 20
+	tests := []struct {
+		name   string
+		req    *Request
+		expect createUser
+	}{
+		{
+			name: "json",
+			req:  newBindRequest(`{"email":"a@example.com","role":"admin","age":20}`, "application/json", map[string]string{"org_id": "1"}),
+			expect: createUser{
+				OrgID:  1,
+				Token:  "secret",
+				Email:  "a@example.com",
+				Role:   "admin",
+				Age:    &age,
+				Tags:   []string{"a", "b"},
+				Dryrun: true,
+			},
+		},
+		{
+			name: "form",
+			req:  newBindRequest(url.Values{"email": {"a@example.com"}, "role": {"member"}}.Encode(), "application/x-www-form-urlencoded", map[string]string{"org_id": "2"}),
+			expect: createUser{
+				OrgID:  2,
+				Token:  "secret",
+				Email:  "a@example.com",
+				Role:   "member",
+				Tags:   []string{"a", "b"},
+				Dryrun: true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := createUser{}
+			if err := Bind(test.req, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("expect %+v, got %+v", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *Request
+		status int
+		expect []jsonapi.ErrorSource
+	}{
+		{
+			name:   "validation",
+			req:    newBindRequest(`{"email":"invalid","role":"owner"}`, "application/json", nil),
+			status: http.StatusUnprocessableEntity,
+			expect: []jsonapi.ErrorSource{{Pointer: "/email"}, {Pointer: "/role"}},
+		},
+		{
+			name:   "required",
+			req:    newBindRequest(`{}`, "application/json", nil),
+			status: http.StatusUnprocessableEntity,
+			expect: []jsonapi.ErrorSource{{Pointer: "/email"}},
+		},
+		{
+			name:   "invalid param",
+			req:    newBindRequest(`{"email":"a@example.com"}`, "application/json", map[string]string{"org_id": "abc"}),
+			status: http.StatusBadRequest,
+			expect: []jsonapi.ErrorSource{{Parameter: "org_id"}},
+		},
+		{
+			name:   "type mismatch",
+			req:    newBindRequest(`{"email":"a@example.com","age":"twenty"}`, "application/json", nil),
+			status: http.StatusBadRequest,
+			expect: []jsonapi.ErrorSource{{Pointer: "/age"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Bind(test.req, &createUser{})
+			errs, ok := err.(jsonapi.Errors)
+			if !ok {
+				t.Fatalf("expected jsonapi.Errors, got %T: %v", err, err)
+			}
+
+			if errs.HTTPStatus() != test.status {
+				t.Errorf("expect status %d, got %d", test.status, errs.HTTPStatus())
+			}
+
+			sources := []jsonapi.ErrorSource{}
+			for _, e := range errs {
+				sources = append(sources, *e.Source)
+			}
+
+			if !reflect.DeepEqual(sources, test.expect) {
+				t.Errorf("expect %+v, got %+v", test.expect, sources)
+			}
+		})
+	}
+}
+
+func TestBindIgnoresBodyForOtherSources(t *testing.T) {
+	req := newBindRequest(`{"OrgID":9,"Token":"forged","Tags":["x"],"Dryrun":true,"email":"a@example.com"}`, "application/json", nil)
+	req.Request.Header.Del("X-Token")
+	req.Request.URL.RawQuery = ""
+
+	got := createUser{}
+	err := Bind(req, &got)
+	errs, ok := err.(jsonapi.Errors)
+	if !ok || len(errs) != 1 || errs[0].Source.Header != "X-Token" {
+		t.Fatalf("expected the missing header to fail required, got %v", err)
+	}
+
+	if expect := (createUser{Email: "a@example.com"}); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %+v, got %+v", expect, got)
+	}
+}
+
+func TestBindInvalidTag(t *testing.T) {
+	type invalid struct {
+		Name string `json:"name" validate:"required,unknown"`
+	}
+
+	for i := 0; i < 2; i++ {
+		err := Bind(newBindRequest(`{"name":"gooo"}`, "application/json", nil), &invalid{})
+		if err == nil || !strings.Contains(err.Error(), `invalid validate tag on invalid.Name: unknown validation rule "unknown"`) {
+			t.Fatalf("expected the invalid tag to be reported, got %v", err)
+		}
+
+		if _, ok := err.(jsonapi.Errors); ok {
+			t.Fatalf("expected a plain error, got %v", err)
+		}
+	}
+}
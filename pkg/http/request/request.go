@@ -9,6 +9,7 @@ import (
 
 	"github.com/version-1/gooo/pkg/context"
 	"github.com/version-1/gooo/pkg/logger"
+	"github.com/version-1/gooo/pkg/presenter/jsonapi"
 )
 
 type ParamParser interface {
@@ -21,7 +22,7 @@ type Request struct {
 	*http.Request
 }
 
-func MarshalBody[T json.Unmarshaler](r *Request, obj *T) error {
+func MarshalBody[T any](r *Request, obj *T) error {
 	b, err := io.ReadAll(r.Request.Body)
 	if err != nil {
 		return err
@@ -32,8 +33,7 @@ func MarshalBody[T json.Unmarshaler](r *Request, obj *T) error {
 }
 
 func (r Request) Logger() logger.Logger {
-	cfg := context.AppConfig(r.Request.Context())
-	return cfg.Logger
+	return context.Logger(r.Request.Context())
 }
 
 func (r Request) Param(key string) (string, bool) {
@@ -44,6 +44,17 @@ func (r Request) ParamInt(key string) (int, bool) {
 	return r.Handler.ParamInt(r.Request.URL.Path, key)
 }
 
+// JSONAPIQuery parses fields, include, sort, filter and page parameters.
+func (r Request) JSONAPIQuery() (jsonapi.Query, error) {
+	return jsonapi.ParseQuery(r.Request.URL.Query())
+}
+
+// JSONAPIDocument decodes and validates a JSON:API request document.
+func (r Request) JSONAPIDocument(opts jsonapi.DecodeOptions) (*jsonapi.Document, error) {
+	defer r.Request.Body.Close()
+	return jsonapi.Decode(r.Request.Body, opts)
+}
+
 func (r Request) Query(key string) (string, bool) {
 	v := r.Request.URL.Query().Get(key)
 	return v, v != ""
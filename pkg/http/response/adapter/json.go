@@ -0,0 +1,66 @@
+package adapter
+
+import (
+	"encoding/json"
+
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+)
+
+// JSON renders payloads with encoding/json and errors as
+// `{"errors": [{"status", "code", "title", "detail", "source"}]}`.
+type JSON struct{}
+
+type jsonError struct {
+	Status int         `json:"status"`
+	Code   string      `json:"code,omitempty"`
+	Title  string      `json:"title,omitempty"`
+	Detail string      `json:"detail,omitempty"`
+	Source *jsonSource `json:"source,omitempty"`
+}
+
+type jsonSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+func (a JSON) ContentType() string {
+	return "application/json"
+}
+
+func (a JSON) Render(payload any, options ...any) ([]byte, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return []byte{}, goooerrors.Wrap(err)
+	}
+
+	return b, nil
+}
+
+func (a JSON) RenderError(e error, options ...any) ([]byte, error) {
+	_, errs, err := resolveError(e, options...)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	list := make([]jsonError, 0, len(errs))
+	for _, it := range errs {
+		je := jsonError{
+			Status: it.Status,
+			Code:   it.Code,
+			Title:  it.Title,
+			Detail: it.Detail,
+		}
+		if it.Source != nil {
+			je.Source = &jsonSource{
+				Pointer:   it.Source.Pointer,
+				Parameter: it.Source.Parameter,
+				Header:    it.Source.Header,
+			}
+		}
+
+		list = append(list, je)
+	}
+
+	return a.Render(map[string]any{"errors": list}, options...)
+}
@@ -2,6 +2,7 @@ package adapter
 
 import (
 	"fmt"
+	"io"
 
 	goooerrors "github.com/version-1/gooo/pkg/errors"
 	"github.com/version-1/gooo/pkg/presenter/jsonapi"
@@ -13,6 +14,14 @@ type JSONAPI struct {
 
 type JSONAPIOption struct {
 	Meta jsonapi.Serializer
+	// Query trims the attributes to the sparse fieldsets and filters the
+	// included resources by the include paths.
+	Query *jsonapi.Query
+	// Pagination adds the pagination links and meta to collections.
+	Pagination *jsonapi.Pagination
+	// JSONAPI is written as the top level jsonapi object, e.g.
+	// jsonapi.NewObject().
+	JSONAPI *jsonapi.Object
 }
 
 type JSONAPIInvalidTypeError struct {
@@ -31,6 +40,16 @@ func (a *JSONAPI) Render(payload any, options ...any) ([]byte, error) {
 	return resolve(payload, options...)
 }
 
+// RenderTo streams the document to w instead of building it in memory.
+func (a *JSONAPI) RenderTo(w io.Writer, payload any, options ...any) error {
+	doc, err := document(payload, options...)
+	if err != nil {
+		return err
+	}
+
+	return doc.Encode(w)
+}
+
 func RenderMany[T jsonapi.Resourcer](list []T, options ...any) ([]byte, error) {
 	return resolve(list, options...)
 }
@@ -40,66 +59,97 @@ func (a *JSONAPI) RenderError(e error, options ...any) ([]byte, error) {
 	return b, err
 }
 
+// jsonapiDocument is a single or a collection document.
+type jsonapiDocument interface {
+	Serialize() (string, error)
+	Encode(w io.Writer) error
+}
+
 func resolve(payload any, options ...any) ([]byte, error) {
-	var meta jsonapi.Serializer
-	for _, opt := range options {
-		switch t := opt.(type) {
+	doc, err := document(payload, options...)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	s, err := doc.Serialize()
+	return []byte(s), err
+}
+
+func jsonapiOption(options ...any) JSONAPIOption {
+	opt := JSONAPIOption{}
+	for _, o := range options {
+		switch t := o.(type) {
 		case JSONAPIOption:
-			meta = t.Meta
+			opt = t
 		case *JSONAPIOption:
-			meta = t.Meta
+			opt = *t
 		}
 	}
 
+	return opt
+}
+
+func document(payload any, options ...any) (jsonapiDocument, error) {
+	opt := jsonapiOption(options...)
+
 	switch v := payload.(type) {
 	case jsonapi.Resourcer:
 		data, includes := v.ToJSONAPIResource()
-		r, err := jsonapi.New(data, includes, meta)
-		if err != nil {
-			return []byte{}, err
+		if opt.Query != nil {
+			list, filtered := opt.Query.Apply([]jsonapi.Resource{data}, includes)
+			data, includes = list[0], filtered
 		}
 
-		s, err := r.Serialize()
-		return []byte(s), err
-	case []jsonapi.Resourcer:
-		r, err := jsonapi.NewManyFrom(v, meta)
+		r, err := jsonapi.New(data, includes, opt.Meta)
 		if err != nil {
-			return []byte{}, err
+			return nil, err
 		}
+		r.JSONAPI = opt.JSONAPI
 
-		s, err := r.Serialize()
-		return []byte(s), err
+		return r, nil
+	case []jsonapi.Resourcer:
+		return documentMany(v, opt)
 	case jsonapi.Resourcers:
-		r, err := jsonapi.NewManyFrom(v, meta)
-		if err != nil {
-			return []byte{}, err
-		}
-
-		s, err := r.Serialize()
-		return []byte(s), err
+		return documentMany(v, opt)
 	default:
-		return []byte{}, goooerrors.Wrap(JSONAPIInvalidTypeError{Payload: v})
+		return nil, goooerrors.Wrap(JSONAPIInvalidTypeError{Payload: v})
+	}
+}
+
+func documentMany(list []jsonapi.Resourcer, opt JSONAPIOption) (jsonapiDocument, error) {
+	r, err := jsonapi.NewManyFrom(list, opt.Meta)
+	if err != nil {
+		return nil, err
+	}
+	r.JSONAPI = opt.JSONAPI
+
+	if opt.Query != nil {
+		r.Data.Data, r.Included = opt.Query.Apply(r.Data.Data, r.Included)
 	}
+
+	if opt.Pagination != nil {
+		r.Links = opt.Pagination.Links()
+		r.Meta = jsonapi.MergedMeta{opt.Pagination, opt.Meta}
+	}
+
+	return r, nil
 }
 
 func resolveError(e error, options ...any) ([]byte, []jsonapi.Error, error) {
+	var errors jsonapi.Errors
 	switch v := e.(type) {
 	case jsonapi.Errors:
-		s, err := jsonapi.NewErrors(v).Serialize()
-		return []byte(s), v, err
+		errors = v
 	case jsonapi.Error:
-		errors := jsonapi.Errors{v}
-		s, err := jsonapi.NewErrors(errors).Serialize()
-		return []byte(s), errors, err
+		errors = jsonapi.Errors{v}
 	case jsonapi.Errable:
-		obj := v.ToJSONAPIError()
-		errors := jsonapi.Errors{obj}
-		s, err := jsonapi.NewErrors(errors).Serialize()
-		return []byte(s), errors, err
+		errors = jsonapi.Errors{v.ToJSONAPIError()}
 	default:
-		obj := jsonapi.NewErrorResponse(v).ToJSONAPIError()
-		errors := jsonapi.Errors{obj}
-		s, err := jsonapi.NewErrors(errors).Serialize()
-		return []byte(s), errors, err
+		errors = jsonapi.Errors{jsonapi.NewErrorResponse(v).ToJSONAPIError()}
 	}
+
+	r := jsonapi.NewErrors(errors)
+	r.JSONAPI = jsonapiOption(options...).JSONAPI
+	s, err := r.Serialize()
+	return []byte(s), errors, err
 }
@@ -268,7 +268,91 @@ func TestJSONAPIRender(t *testing.T) {
 				return true
 			},
 		},
+		{
+			Name: "RenderError with source",
+			Subject: func(t *testing.T) ([]byte, error) {
+				s, err := a.RenderError(jsonapi.Errors{
+					{
+						ID:     "error-id",
+						Status: 422,
+						Code:   "required",
+						Title:  "Invalid Attribute",
+						Detail: "email can not be empty",
+						Source: &jsonapi.ErrorSource{Pointer: "/email"},
+					},
+				})
+				if err != nil {
+					return []byte{}, err
+				}
+
+				buffer := &bytes.Buffer{}
+				err = json.Compact(buffer, s)
+				return buffer.Bytes(), err
+			},
+			Expect: func(t *testing.T) ([]byte, error) {
+				s := `{
+					"data": null,
+					"errors": [
+						{
+							"id": "error-id",
+							"status": 422,
+							"code": "required",
+							"title": "Invalid Attribute",
+							"detail": "email can not be empty",
+							"source": { "pointer": "/email" }
+						}
+					]
+				}`
+
+				buffer := &bytes.Buffer{}
+				err := json.Compact(buffer, []byte(s))
+				return buffer.Bytes(), err
+			},
+			Assert: func(t *testing.T, r *goootesting.Record[[]byte, []byte]) bool {
+				e, err := r.Expect(t)
+				s, serr := r.Subject(t)
+
+				if !reflect.DeepEqual(e, s) {
+					t.Errorf("Expected %s, got %s", e, s)
+					return false
+				}
+
+				if serr != nil && err.Error() != serr.Error() {
+					t.Errorf("Expected %v, got %v", err, serr)
+					return false
+				}
+				return true
+			},
+		},
 	})
 
 	test.Run(t)
 }
+
+func TestJSONAPIRenderTo(t *testing.T) {
+	a := JSONAPI{}
+	list := []jsonapi.Resourcer{
+		dummy{ID: "1", String: "<string>", Number: 1},
+		dummy{ID: "2", String: "string", Number: 2},
+	}
+	option := JSONAPIOption{Meta: meta{Key: "value"}}
+
+	expect, err := a.Render(list, option)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := a.RenderTo(buffer, list, option); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(expect, buffer.Bytes()) {
+		t.Errorf("Expected %s, got %s", expect, buffer.Bytes())
+	}
+
+	buffer.Reset()
+	if err := a.RenderTo(buffer, dummy{}); err == nil || buffer.Len() > 0 {
+		t.Errorf("Expected an error without output for a resource without ID, got %v %q", err, buffer.String())
+	}
+}
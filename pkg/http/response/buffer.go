@@ -0,0 +1,125 @@
+package response
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// buffer holds the status, headers and body of a response until it is
+// flushed. statusCode and written keep the state of the Response when the
+// buffering started, to be restored when it is discarded.
+type buffer struct {
+	w          http.ResponseWriter
+	header     http.Header
+	status     int
+	body       bytes.Buffer
+	statusCode int
+	written    bool
+}
+
+func (b *buffer) Header() http.Header {
+	return b.header
+}
+
+func (b *buffer) WriteHeader(statusCode int) {
+	if b.status == 0 {
+		b.status = statusCode
+	}
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	return b.body.Write(p)
+}
+
+// Buffer holds what is written from now on in memory until FlushBuffer or
+// DiscardBuffer, so that the response can still be replaced after the handler
+// returns. Buffers nest: FlushBuffer and DiscardBuffer end the innermost one,
+// and a flushed inner buffer is written into the outer one.
+//
+// The buffered writer does not implement http.Flusher, so a handler that
+// streams its response only sends it when the buffering ends.
+func (r *Response) Buffer() {
+	r.ResponseWriter = &buffer{
+		w:          r.ResponseWriter,
+		header:     r.ResponseWriter.Header().Clone(),
+		statusCode: r.statusCode,
+		written:    r.written,
+	}
+}
+
+// FlushBuffer writes the innermost buffered response and stops buffering it.
+func (r *Response) FlushBuffer() error {
+	b, ok := r.ResponseWriter.(*buffer)
+	if !ok {
+		return nil
+	}
+
+	r.ResponseWriter = b.w
+	h := b.w.Header()
+	for k := range h {
+		delete(h, k)
+	}
+	for k, v := range b.header {
+		h[k] = v
+	}
+
+	if b.status == 0 {
+		return nil
+	}
+
+	b.w.WriteHeader(b.status)
+	_, err := b.w.Write(b.body.Bytes())
+	return err
+}
+
+// DiscardBuffer drops the innermost buffered response and stops buffering it,
+// so that another response can be written.
+func (r *Response) DiscardBuffer() {
+	b, ok := r.ResponseWriter.(*buffer)
+	if !ok {
+		return
+	}
+
+	r.ResponseWriter = b.w
+	r.statusCode = b.statusCode
+	r.written = b.written
+}
+
+// streamBufferSize is how much of a streamed payload Render holds back.
+const streamBufferSize = 64 << 10
+
+// streamBuffer holds what is written in memory until it exceeds
+// streamBufferSize, and writes through from then on.
+type streamBuffer struct {
+	w         io.Writer
+	body      bytes.Buffer
+	streaming bool
+}
+
+func (b *streamBuffer) Write(p []byte) (int, error) {
+	if !b.streaming && b.body.Len()+len(p) <= streamBufferSize {
+		return b.body.Write(p)
+	}
+
+	if err := b.flush(); err != nil {
+		return 0, err
+	}
+
+	return b.w.Write(p)
+}
+
+// flush writes what is held back and lets the writes through.
+func (b *streamBuffer) flush() error {
+	if b.streaming {
+		return nil
+	}
+
+	b.streaming = true
+	_, err := b.w.Write(b.body.Bytes())
+	return err
+}
@@ -0,0 +1,163 @@
+package response
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/version-1/gooo/pkg/http/response/adapter"
+)
+
+var jsonAdapter Renderer = &adapter.JSON{}
+
+// Renderers is the registry used by responses without Options.Registry.
+var Renderers = NewRegistry(jsonapiAdapter, jsonAdapter, rawAdapter)
+
+// RegisterRenderer registers r to the default registry for mediaType.
+func RegisterRenderer(mediaType string, r Renderer) {
+	Renderers.Register(mediaType, r)
+}
+
+// Registry keeps renderers by media type in the order of registration, which
+// is the order of preference when the Accept header has wildcards.
+type Registry struct {
+	mu         sync.RWMutex
+	mediaTypes []string
+	renderers  map[string]Renderer
+}
+
+// NewRegistry registers the renderers by their content type.
+func NewRegistry(renderers ...Renderer) *Registry {
+	r := &Registry{renderers: map[string]Renderer{}}
+	for _, rd := range renderers {
+		r.Register(rd.ContentType(), rd)
+	}
+
+	return r
+}
+
+func (r *Registry) Register(mediaType string, rd Renderer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := r.renderers[mediaType]; !ok {
+		r.mediaTypes = append(r.mediaTypes, mediaType)
+	}
+	r.renderers[mediaType] = rd
+}
+
+func (r *Registry) Lookup(mediaType string) (Renderer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rd, ok := r.renderers[strings.ToLower(mediaType)]
+	return rd, ok
+}
+
+// Negotiate returns the renderer that best matches the Accept header. The
+// fallback is preferred whenever it is acceptable at the same quality, and
+// is returned as is for an empty header.
+func (r *Registry) Negotiate(accept string, fallback Renderer) (Renderer, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return fallback, true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ranges, excluded := parseAccept(accept)
+	acceptable := func(ar acceptRange, mediaType string) bool {
+		if !ar.match(mediaType) {
+			return false
+		}
+
+		for _, ex := range excluded {
+			if ex.match(mediaType) && ex.specificity() > ar.specificity() {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, ar := range ranges {
+		if fallback != nil && acceptable(ar, fallback.ContentType()) {
+			return fallback, true
+		}
+
+		for _, mt := range r.mediaTypes {
+			if acceptable(ar, mt) {
+				return r.renderers[mt], true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+type acceptRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+func (a acceptRange) specificity() int {
+	switch {
+	case a.typ == "*":
+		return 0
+	case a.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (a acceptRange) match(mediaType string) bool {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	return (a.typ == "*" || a.typ == typ) && (a.subtype == "*" || a.subtype == subtype)
+}
+
+// parseAccept returns the acceptable ranges ordered by quality, specificity
+// and position, and the ranges excluded with q=0.
+func parseAccept(accept string) ([]acceptRange, []acceptRange) {
+	ranges := []acceptRange{}
+	excluded := []acceptRange{}
+	for _, s := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(s))
+		if err != nil {
+			continue
+		}
+
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		ar := acceptRange{typ: typ, subtype: subtype, q: q}
+		if q <= 0 {
+			excluded = append(excluded, ar)
+			continue
+		}
+
+		ranges = append(ranges, ar)
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+
+	return ranges, excluded
+}
@@ -0,0 +1,79 @@
+package response
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+type csvAdapter struct{}
+
+func (csvAdapter) ContentType() string { return "text/csv" }
+
+func (csvAdapter) Render(payload any, options ...any) ([]byte, error) { return []byte{}, nil }
+
+func (csvAdapter) RenderError(err error, options ...any) ([]byte, error) { return []byte{}, nil }
+
+func TestRegistryNegotiate(t *testing.T) {
+	registry := NewRegistry(jsonapiAdapter, jsonAdapter)
+	registry.Register("text/csv", csvAdapter{})
+
+	tests := []struct {
+		name     string
+		accept   string
+		fallback Renderer
+		expect   string
+		ok       bool
+	}{
+		{name: "empty", accept: "", fallback: jsonapiAdapter, expect: "application/vnd.api+json", ok: true},
+		{name: "wildcard keeps fallback", accept: "*/*", fallback: jsonapiAdapter, expect: "application/vnd.api+json", ok: true},
+		{name: "exact", accept: "application/json", fallback: jsonapiAdapter, expect: "application/json", ok: true},
+		{name: "custom", accept: "text/csv", fallback: jsonapiAdapter, expect: "text/csv", ok: true},
+		{name: "quality", accept: "application/vnd.api+json;q=0.5, application/json", fallback: jsonapiAdapter, expect: "application/json", ok: true},
+		{name: "specificity", accept: "application/*, application/json", fallback: jsonapiAdapter, expect: "application/json", ok: true},
+		{name: "subtype wildcard uses registration order", accept: "text/*", fallback: jsonapiAdapter, expect: "text/csv", ok: true},
+		{name: "excluded", accept: "application/vnd.api+json;q=0, */*;q=0.1", fallback: jsonapiAdapter, expect: "application/json", ok: true},
+		{name: "not acceptable", accept: "image/png", fallback: jsonapiAdapter, ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := registry.Negotiate(test.accept, test.fallback)
+			if ok != test.ok {
+				t.Fatalf("expect ok %v, got %v", test.ok, ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			if got.ContentType() != test.expect {
+				t.Errorf("expect %s, got %s", test.expect, got.ContentType())
+			}
+		})
+	}
+}
+
+func TestResponseNegotiate(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := New(rec, Options{Adapter: "jsonapi"})
+
+	if !w.Negotiate("application/json") {
+		t.Fatal("expected application/json to be acceptable")
+	}
+
+	if err := w.Render(map[string]string{"name": "gooo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expect application/json, got %s", ct)
+	}
+
+	if body := rec.Body.String(); body != `{"name":"gooo"}` {
+		t.Errorf("unexpected body %s", body)
+	}
+
+	if w.Negotiate("image/png") {
+		t.Error("expected image/png not to be acceptable")
+	}
+}
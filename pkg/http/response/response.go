@@ -2,10 +2,13 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/version-1/gooo/pkg/http/response/adapter"
 	"github.com/version-1/gooo/pkg/logger"
+	"github.com/version-1/gooo/pkg/presenter/jsonapi"
 )
 
 var _ http.ResponseWriter = &Response{}
@@ -19,6 +22,17 @@ type Renderer interface {
 	RenderError(err error, options ...any) ([]byte, error)
 }
 
+// StreamRenderer is implemented by renderers which can write the payload to
+// the response directly. Render prefers it over Renderer.Render, and holds the
+// first streamBufferSize bytes back, so that an error rendering a smaller
+// payload leaves the response unwritten for an error response. An error after
+// that leaves a truncated body.
+type StreamRenderer interface {
+	RenderTo(w io.Writer, payload any, options ...any) error
+}
+
+var _ Logger = logger.Logger(nil)
+
 type Logger interface {
 	Infof(format string, args ...any)
 	Errorf(format string, args ...any)
@@ -26,7 +40,9 @@ type Logger interface {
 
 type Options struct {
 	Adapter string
-	logger  Logger
+	Logger  Logger
+	// Registry is used to negotiate the renderer. Renderers is used when nil.
+	Registry *Registry
 }
 
 type Response struct {
@@ -34,6 +50,7 @@ type Response struct {
 	adapter        Renderer
 	options        Options
 	statusCode     int
+	written        bool
 }
 
 func New(r http.ResponseWriter, opts Options) *Response {
@@ -41,6 +58,8 @@ func New(r http.ResponseWriter, opts Options) *Response {
 	switch opts.Adapter {
 	case "jsonapi":
 		adp = jsonapiAdapter
+	case "json":
+		adp = jsonAdapter
 	default:
 		opts.Adapter = "raw"
 	}
@@ -54,8 +73,8 @@ func New(r http.ResponseWriter, opts Options) *Response {
 }
 
 func (r Response) logger() Logger {
-	if r.options.logger != nil {
-		return r.options.logger
+	if r.options.Logger != nil {
+		return r.options.Logger
 	}
 
 	return logger.DefaultLogger
@@ -75,15 +94,36 @@ func (r *Response) SetAdapter(adp Renderer) *Response {
 	return r
 }
 
+func (r Response) registry() *Registry {
+	if r.options.Registry != nil {
+		return r.options.Registry
+	}
+
+	return Renderers
+}
+
+// Negotiate switches the adapter to the one that best matches the Accept
+// header. It keeps the current adapter and returns false when nothing matches.
+func (r *Response) Negotiate(accept string) bool {
+	r.Header().Add("Vary", "Accept")
+	adp, ok := r.registry().Negotiate(accept, r.adapter)
+	if !ok {
+		return false
+	}
+
+	r.adapter = adp
+	return true
+}
+
 func (r *Response) JSON(payload any) *Response {
 	r.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(r.ResponseWriter).Encode(payload)
+	json.NewEncoder(r).Encode(payload)
 
 	return r
 }
 
 func (r *Response) Body(payload string) *Response {
-	r.ResponseWriter.Write([]byte(payload))
+	r.Write([]byte(payload))
 
 	return r
 }
@@ -93,6 +133,15 @@ func (r *Response) StatusCode() int {
 }
 
 func (r *Response) Render(payload any, options ...any) error {
+	if s, ok := r.Adapter().(StreamRenderer); ok {
+		buf := &streamBuffer{w: r}
+		if err := s.RenderTo(buf, payload, options...); err != nil {
+			return err
+		}
+
+		return buf.flush()
+	}
+
 	b, err := r.Adapter().Render(payload, options...)
 	if err != nil {
 		return err
@@ -116,12 +165,19 @@ func (r Response) Header() http.Header {
 }
 
 func (r *Response) Write(b []byte) (int, error) {
+	r.written = true
 	return r.ResponseWriter.Write(b)
 }
 
 func (r *Response) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
 	r.statusCode = statusCode
+	r.written = true
+}
+
+// Written reports whether the status or the body has been sent.
+func (r Response) Written() bool {
+	return r.written
 }
 
 func (r *Response) InternalServerError() {
@@ -144,6 +200,18 @@ func (r *Response) Forbidden() {
 	r.WriteHeader(http.StatusForbidden)
 }
 
+func (r *Response) MethodNotAllowed() {
+	r.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func (r *Response) NotAcceptable() {
+	r.WriteHeader(http.StatusNotAcceptable)
+}
+
+func (r *Response) UnprocessableEntity() {
+	r.WriteHeader(http.StatusUnprocessableEntity)
+}
+
 func (r *Response) renderErrorWith(fn func(), e error, options ...any) error {
 	r.logger().Errorf("%+v", e)
 	b, err := r.Adapter().RenderError(e, options...)
@@ -180,3 +248,27 @@ func (r *Response) UnauthorizedWith(e error, options ...any) error {
 func (r *Response) ForbiddenWith(e error, options ...any) error {
 	return r.renderErrorWith(r.Forbidden, e, options...)
 }
+
+func (r *Response) MethodNotAllowedWith(e error, options ...any) error {
+	return r.renderErrorWith(r.MethodNotAllowed, e, options...)
+}
+
+func (r *Response) NotAcceptableWith(e error, options ...any) error {
+	return r.renderErrorWith(r.NotAcceptable, e, options...)
+}
+
+func (r *Response) UnprocessableEntityWith(e error, options ...any) error {
+	return r.renderErrorWith(r.UnprocessableEntity, e, options...)
+}
+
+// ErrorWith renders e with the status it carries through
+// jsonapi.StatusGetter, or 500 when it has none.
+func (r *Response) ErrorWith(e error, options ...any) error {
+	status := http.StatusInternalServerError
+	var sg jsonapi.StatusGetter
+	if errors.As(e, &sg) {
+		status = sg.HTTPStatus()
+	}
+
+	return r.renderErrorWith(func() { r.WriteHeader(status) }, e, options...)
+}
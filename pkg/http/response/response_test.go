@@ -0,0 +1,66 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// streamAdapter streams size bytes of the payload and then fails with err.
+type streamAdapter struct {
+	csvAdapter
+	size int
+	err  error
+}
+
+func (a streamAdapter) RenderTo(w io.Writer, payload any, options ...any) error {
+	if _, err := io.WriteString(w, strings.Repeat("x", a.size)); err != nil {
+		return err
+	}
+
+	return a.err
+}
+
+func TestResponseRenderStream(t *testing.T) {
+	renderErr := errors.New("render failed")
+	tests := []struct {
+		name    string
+		adapter streamAdapter
+		written bool
+	}{
+		{name: "success", adapter: streamAdapter{size: streamBufferSize * 2}, written: true},
+		{name: "error within the buffer", adapter: streamAdapter{size: streamBufferSize, err: renderErr}, written: false},
+		{name: "error after the buffer", adapter: streamAdapter{size: streamBufferSize + 1, err: renderErr}, written: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			w := New(rec, Options{})
+			w.SetAdapter(test.adapter)
+
+			err := w.Render(nil)
+			if !errors.Is(err, test.adapter.err) {
+				t.Errorf("expect error %v, got %v", test.adapter.err, err)
+			}
+
+			if w.Written() != test.written || (rec.Body.Len() > 0) != test.written {
+				t.Errorf("expect written %t, got %t with %d bytes", test.written, w.Written(), rec.Body.Len())
+			}
+
+			if test.written && err == nil && rec.Body.Len() != test.adapter.size {
+				t.Errorf("expect %d bytes, got %d", test.adapter.size, rec.Body.Len())
+			}
+
+			if !test.written {
+				w.BadRequest()
+				if rec.Code != http.StatusBadRequest {
+					t.Errorf("expect status %d, got %d", http.StatusBadRequest, rec.Code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Encoder = TextEncoder{}
+var _ Encoder = JSONEncoder{}
+
+type Encoder interface {
+	Encode(w io.Writer, e Entry) error
+}
+
+// TextEncoder writes `[LEVEL] date message key=value` lines.
+type TextEncoder struct {
+	Color bool
+}
+
+func (t TextEncoder) Encode(w io.Writer, e Entry) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString(t.label(e.Level))
+	buf.WriteByte(' ')
+	buf.WriteString(t.colorize(Gray, e.Time.Format(DateLayout)))
+	buf.WriteByte(' ')
+	buf.WriteString(e.Message)
+
+	for _, f := range e.Fields {
+		buf.WriteByte(' ')
+		buf.WriteString(t.colorize(Gray, f.Key+"="))
+		buf.WriteString(textValue(f.Value))
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (t TextEncoder) label(level LogLevel) string {
+	label := "[" + level.String() + "]"
+	switch level {
+	case LogLevelDebug:
+		return t.colorize(Magenta, label)
+	case LogLevelInfo:
+		return t.colorize(Cyan, label)
+	case LogLevelWarn:
+		return t.colorize(Yellow, label)
+	default:
+		return t.colorize(Red, label)
+	}
+}
+
+func (t TextEncoder) colorize(c, msg string) string {
+	if !t.Color {
+		return msg
+	}
+
+	return WithColor(c, msg)
+}
+
+func textValue(v any) string {
+	var s string
+	switch vv := v.(type) {
+	case nil:
+		return "<nil>"
+	case error:
+		s = vv.Error()
+	case time.Time:
+		s = vv.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		s = vv.String()
+	default:
+		s = fmt.Sprintf("%+v", vv)
+	}
+
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+// JSONEncoder writes one JSON object per entry with time, level and msg keys
+// followed by the fields.
+type JSONEncoder struct{}
+
+func (j JSONEncoder) Encode(w io.Writer, e Entry) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"time":`)
+	if err := writeJSON(buf, e.Time.Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+
+	buf.WriteString(`,"level":`)
+	if err := writeJSON(buf, strings.ToLower(e.Level.String())); err != nil {
+		return err
+	}
+
+	buf.WriteString(`,"msg":`)
+	if err := writeJSON(buf, e.Message); err != nil {
+		return err
+	}
+
+	for _, f := range e.Fields {
+		buf.WriteByte(',')
+		if err := writeJSON(buf, f.Key); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := writeJSON(buf, jsonValue(f.Value)); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func jsonValue(v any) any {
+	switch vv := v.(type) {
+	case error:
+		return vv.Error()
+	case json.Marshaler:
+		return vv
+	case fmt.Stringer:
+		return vv.String()
+	default:
+		return vv
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, err = json.Marshal(fmt.Sprintf("%+v", v))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = buf.Write(b)
+	return err
+}
@@ -2,19 +2,24 @@ package logger
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Logger interface {
+	Debugf(format string, args ...interface{})
 	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
 	Errorf(format string, args ...interface{})
 	Fatalf(format string, args ...interface{})
+	// With returns a child logger that adds the key/value pairs to every entry.
+	With(args ...any) Logger
 }
 
-type defaultLogger struct {
-	level LogLevel
-}
+var _ Logger = &StructuredLogger{}
 
 type LogLevel int
 
@@ -26,81 +31,227 @@ const (
 	LogLevelFatal
 )
 
-var DefaultLogger = defaultLogger{
-	level: LogLevelInfo,
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	case LogLevelFatal:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
 }
 
-func InfoLabel() string {
-	return WithColor(Cyan, "[INFO]")
+var DefaultLogger = New(Options{
+	Level:   LogLevelInfo,
+	Encoder: TextEncoder{Color: true},
+})
+
+type Field struct {
+	Key   string
+	Value any
 }
 
-func ErrorLabel() string {
-	return WithColor(Red, "[ERROR]")
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
 }
 
-func WarnLabel() string {
-	return WithColor(Yellow, "[WARN]")
+type Entry struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+	Fields  []Field
 }
 
-func DateLabel() string {
-	return DateFormat(time.Now())
+type Options struct {
+	Level   LogLevel
+	Encoder Encoder
+	Output  io.Writer
 }
 
-func DateFormat(t time.Time) string {
-	return WithColor(Gray, t.Format("2006-01-02T15:04:05 -0700"))
+// StructuredLogger writes leveled entries with key/value fields through an
+// Encoder. Child loggers created by With share the output and the level of the
+// parent, so SetLevel on the parent applies to them as well.
+type StructuredLogger struct {
+	level   *atomic.Int32
+	encoder Encoder
+	out     *output
+	fields  []Field
 }
 
-func (l *defaultLogger) SetLevel(level LogLevel) {
-	l.level = level
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
 }
 
-func (l defaultLogger) SInfof(format string, args ...any) string {
-	return fmt.Sprintf(fmt.Sprintf("%s %s %s\n", InfoLabel(), DateLabel(), format), args...)
+func New(opts Options) *StructuredLogger {
+	if opts.Encoder == nil {
+		opts.Encoder = TextEncoder{}
+	}
+
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	l := &StructuredLogger{
+		level:   &atomic.Int32{},
+		encoder: opts.Encoder,
+		out:     &output{w: opts.Output},
+	}
+	l.level.Store(int32(opts.Level))
+
+	return l
 }
 
-func (l defaultLogger) SErrorf(format string, args ...any) string {
-	return fmt.Sprintf(fmt.Sprintf("%s %s %s\n", ErrorLabel(), DateLabel(), format), args...)
+// SetLevel is safe to call while other goroutines are logging.
+func (l *StructuredLogger) SetLevel(level LogLevel) {
+	l.level.Store(int32(level))
 }
 
-func (l defaultLogger) SWarnf(format string, args ...any) string {
-	return fmt.Sprintf(fmt.Sprintf("%s %s %s\n", WarnLabel(), DateLabel(), format), args...)
+func (l *StructuredLogger) Level() LogLevel {
+	return LogLevel(l.level.Load())
 }
 
-func (l defaultLogger) Debugf(format string, args ...interface{}) {
-	if l.level >= LogLevelInfo {
-		return
-	}
-	fmt.Printf(l.SInfof(format, args...))
+func (l *StructuredLogger) Enabled(level LogLevel) bool {
+	return level >= l.Level()
 }
 
-func (l defaultLogger) Infof(format string, args ...interface{}) {
-	if l.level > LogLevelInfo {
-		return
-	}
-	fmt.Printf(l.SInfof(format, args...))
+func (l *StructuredLogger) With(args ...any) Logger {
+	return l.with(toFields(args)...)
+}
+
+func (l *StructuredLogger) with(fields ...Field) *StructuredLogger {
+	child := *l
+	child.fields = make([]Field, 0, len(l.fields)+len(fields))
+	child.fields = append(child.fields, l.fields...)
+	child.fields = append(child.fields, fields...)
+
+	return &child
+}
+
+func (l *StructuredLogger) Fields() []Field {
+	return l.fields
 }
 
-func (l defaultLogger) Errorf(format string, args ...interface{}) {
-	if l.level > LogLevelError {
+// Log writes msg with the logger fields followed by the key/value pairs.
+func (l *StructuredLogger) Log(level LogLevel, msg string, args ...any) {
+	if !l.Enabled(level) {
 		return
 	}
-	fmt.Printf(l.SErrorf(format, args...))
+
+	fields := l.fields
+	if len(args) > 0 {
+		fields = append(append([]Field{}, l.fields...), toFields(args)...)
+	}
+
+	l.write(Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  fields,
+	})
 }
 
-func (l defaultLogger) Warnf(format string, args ...interface{}) {
-	if l.level > LogLevelWarn {
-		return
+func (l *StructuredLogger) write(e Entry) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+
+	if err := l.encoder.Encode(l.out.w, e); err != nil {
+		fmt.Fprintf(os.Stderr, "pkg/logger: failed to encode entry: %s\n", err)
 	}
-	fmt.Printf(l.SWarnf(format, args...))
 }
 
-func (l defaultLogger) Fatalf(format string, args ...interface{}) {
-	if l.level > LogLevelFatal {
-		return
+func (l *StructuredLogger) Debugf(format string, args ...interface{}) {
+	l.Log(LogLevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Infof(format string, args ...interface{}) {
+	l.Log(LogLevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Warnf(format string, args ...interface{}) {
+	l.Log(LogLevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Errorf(format string, args ...interface{}) {
+	l.Log(LogLevelError, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Fatalf(format string, args ...interface{}) {
+	l.Log(LogLevelFatal, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func (l *StructuredLogger) SInfof(format string, args ...any) string {
+	return fmt.Sprintf(fmt.Sprintf("%s %s %s\n", InfoLabel(), DateLabel(), format), args...)
+}
+
+func (l *StructuredLogger) SErrorf(format string, args ...any) string {
+	return fmt.Sprintf(fmt.Sprintf("%s %s %s\n", ErrorLabel(), DateLabel(), format), args...)
+}
+
+func (l *StructuredLogger) SWarnf(format string, args ...any) string {
+	return fmt.Sprintf(fmt.Sprintf("%s %s %s\n", WarnLabel(), DateLabel(), format), args...)
+}
+
+const badKey = "!BADKEY"
+
+// toFields converts alternating key/value pairs into fields the same way as
+// log/slog. A Field can also be passed as is.
+func toFields(args []any) []Field {
+	fields := []Field{}
+	for i := 0; i < len(args); i++ {
+		switch v := args[i].(type) {
+		case Field:
+			fields = append(fields, v)
+		case string:
+			if i+1 >= len(args) {
+				fields = append(fields, Field{Key: badKey, Value: v})
+				continue
+			}
+
+			fields = append(fields, Field{Key: v, Value: args[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{Key: badKey, Value: v})
+		}
 	}
-	log.Fatalf(l.SErrorf(format, args...))
+
+	return fields
+}
+
+func InfoLabel() string {
+	return WithColor(Cyan, "[INFO]")
+}
+
+func ErrorLabel() string {
+	return WithColor(Red, "[ERROR]")
+}
+
+func WarnLabel() string {
+	return WithColor(Yellow, "[WARN]")
 }
 
+func DebugLabel() string {
+	return WithColor(Magenta, "[DEBUG]")
+}
+
+func DateLabel() string {
+	return DateFormat(time.Now())
+}
+
+func DateFormat(t time.Time) string {
+	return WithColor(Gray, t.Format(DateLayout))
+}
+
+const DateLayout = "2006-01-02T15:04:05 -0700"
+
 func WithColor(c, msg string) string {
 	return fmt.Sprintf("%s%s%s", c, msg, Reset)
 }
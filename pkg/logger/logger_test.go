@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStructuredLoggerLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(Options{Level: LogLevelWarn, Output: buf})
+
+	l.Debugf("debug")
+	l.Infof("info")
+	l.Warnf("warn %d", 1)
+	l.Errorf("error %d", 2)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), buf.String())
+	}
+
+	if !strings.HasPrefix(lines[0], "[WARN]") || !strings.HasSuffix(lines[0], "warn 1") {
+		t.Errorf("unexpected line %q", lines[0])
+	}
+
+	if !strings.HasPrefix(lines[1], "[ERROR]") || !strings.HasSuffix(lines[1], "error 2") {
+		t.Errorf("unexpected line %q", lines[1])
+	}
+}
+
+func TestStructuredLoggerSetLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(Options{Level: LogLevelWarn, Output: buf})
+	child := l.With("request_id", "abc")
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		child.Infof("concurrent")
+	}()
+	l.SetLevel(LogLevelInfo)
+	wg.Wait()
+
+	buf.Reset()
+	child.Infof("info")
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), "info request_id=abc") {
+		t.Errorf("expected the child to follow the level of the parent, got %q", buf.String())
+	}
+}
+
+func TestStructuredLoggerTextFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(Options{Level: LogLevelDebug, Output: buf})
+
+	child := l.With("request_id", "abc", "path", "/users/1")
+	child.With(F("user", 10), "err", errors.New("not found")).Infof("done")
+	l.Infof("parent")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expect := `done request_id=abc path=/users/1 user=10 err="not found"`
+	if !strings.HasSuffix(lines[0], expect) {
+		t.Errorf("expected %q to end with %q", lines[0], expect)
+	}
+
+	if !strings.HasSuffix(lines[1], "parent") {
+		t.Errorf("expected parent logger to have no fields, got %q", lines[1])
+	}
+}
+
+func TestStructuredLoggerJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(Options{Level: LogLevelInfo, Encoder: JSONEncoder{}, Output: buf})
+
+	l.With("request_id", "abc", "count", 3, "odd").Errorf("failed: %s", "reason")
+
+	got := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected JSON line, got %s: %s", buf.String(), err)
+	}
+	delete(got, "time")
+
+	expect := map[string]any{
+		"level":      "error",
+		"msg":        "failed: reason",
+		"request_id": "abc",
+		"count":      float64(3),
+		badKey:       "odd",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %v, got %v", expect, got)
+	}
+
+	prefix := `{"time":`
+	if !strings.HasPrefix(buf.String(), prefix) {
+		t.Errorf("expected %s to start with %s", buf.String(), prefix)
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(Options{Level: LogLevelInfo, Encoder: JSONEncoder{}, Output: buf})
+	sl := slog.New(NewSlogHandler(l)).With("app", "gooo").WithGroup("http")
+
+	sl.Debug("ignored")
+	sl.Warn("slow request", "status", 200, slog.Group("req", "method", "GET"))
+
+	got := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected a JSON line, got %s: %s", buf.String(), err)
+	}
+	delete(got, "time")
+
+	expect := map[string]any{
+		"level":           "warn",
+		"msg":             "slow request",
+		"app":             "gooo",
+		"http.status":     float64(200),
+		"http.req.method": "GET",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %v, got %v", expect, got)
+	}
+}
+
+type printer struct {
+	lines []string
+}
+
+func (p *printer) Infof(format string, args ...any) {
+	p.lines = append(p.lines, fmt.Sprintf(format, args...))
+}
+
+func (p *printer) Debugf(format string, args ...any) {
+	p.lines = append(p.lines, fmt.Sprintf(format, args...))
+}
+
+func TestWithRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	WithRequestID(New(Options{Level: LogLevelInfo, Output: buf}), "abc").Infof("query")
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), "query request_id=abc") {
+		t.Errorf("expected the request id as a field, got %q", buf.String())
+	}
+
+	p := &printer{}
+	WithRequestID(p, "abc").Infof("query %d", 1)
+	WithRequestID(p, "").Infof("query %d", 2)
+	if !reflect.DeepEqual(p.lines, []string{"[request_id=abc] query 1", "query 2"}) {
+		t.Errorf("expected the request id as a prefix, got %v", p.lines)
+	}
+}
@@ -0,0 +1,37 @@
+package logger
+
+import "fmt"
+
+// Printer is the part of a Logger required by the packages accepting third
+// party loggers, e.g. the http client and the query logger.
+type Printer interface {
+	Infof(format string, args ...any)
+	Debugf(format string, args ...any)
+}
+
+// WithRequestID tags the entries of l with the request id. A Printer which is
+// not a Logger has no With, so it gets the id as a message prefix instead.
+func WithRequestID(l Printer, id string) Printer {
+	if id == "" {
+		return l
+	}
+
+	if ll, ok := l.(Logger); ok {
+		return ll.With("request_id", id)
+	}
+
+	return prefixPrinter{Printer: l, prefix: fmt.Sprintf("[request_id=%s] ", id)}
+}
+
+type prefixPrinter struct {
+	Printer
+	prefix string
+}
+
+func (p prefixPrinter) Infof(format string, args ...any) {
+	p.Printer.Infof(p.prefix+format, args...)
+}
+
+func (p prefixPrinter) Debugf(format string, args ...any) {
+	p.Printer.Debugf(p.prefix+format, args...)
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+var _ slog.Handler = &SlogHandler{}
+
+// SlogHandler bridges log/slog to a StructuredLogger so that libraries using
+// slog write through the same encoder and output.
+//
+//	slog.New(logger.NewSlogHandler(l))
+type SlogHandler struct {
+	logger *StructuredLogger
+	groups []string
+}
+
+func NewSlogHandler(l *StructuredLogger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(fromSlogLevel(level))
+}
+
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make([]Field, 0, len(h.logger.fields)+r.NumAttrs())
+	fields = append(fields, h.logger.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.groups, a)
+		return true
+	})
+
+	h.logger.write(Entry{
+		Time:    r.Time,
+		Level:   fromSlogLevel(r.Level),
+		Message: r.Message,
+		Fields:  fields,
+	})
+
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := []Field{}
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.groups, a)
+	}
+
+	return &SlogHandler{
+		logger: h.logger.with(fields...),
+		groups: h.groups,
+	}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
+
+	return &SlogHandler{
+		logger: h.logger,
+		groups: append(groups, name),
+	}
+}
+
+func appendAttr(fields []Field, groups []string, a slog.Attr) []Field {
+	v := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(append([]string{}, groups...), a.Key)
+		}
+
+		for _, ga := range v.Group() {
+			fields = appendAttr(fields, groups, ga)
+		}
+
+		return fields
+	}
+
+	key := a.Key
+	for i := len(groups) - 1; i >= 0; i-- {
+		key = groups[i] + "." + key
+	}
+
+	return append(fields, Field{Key: key, Value: v.Any()})
+}
+
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return LogLevelDebug
+	case level < slog.LevelWarn:
+		return LogLevelInfo
+	case level < slog.LevelError:
+		return LogLevelWarn
+	default:
+		return LogLevelError
+	}
+}
@@ -0,0 +1,336 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Document is a decoded request document with a single resource object.
+type Document struct {
+	Data *ResourceObject
+	Meta json.RawMessage
+}
+
+// ResourceObject is a resource object of a request document. Attributes are
+// kept raw until they are unmarshaled into a struct.
+type ResourceObject struct {
+	ID            string
+	LID           string
+	Type          string
+	Attributes    json.RawMessage
+	Relationships map[string]RelationshipData
+	Meta          json.RawMessage
+}
+
+// RelationshipData is the resource linkage of a relationship. Data is empty
+// for an empty to-many or a null to-one relationship.
+type RelationshipData struct {
+	Many bool
+	Data []ResourceIdentifier
+}
+
+// DecodeOptions are the rules of the endpoint the document is sent to.
+type DecodeOptions struct {
+	// Type is the resource type of the endpoint. Other types are a conflict.
+	Type string
+	// ID is the id of the resource being updated. When set, the document
+	// must have the same id.
+	ID string
+	// AllowClientID accepts client-generated ids on creation.
+	AllowClientID bool
+}
+
+// Decode reads a request document and validates it against the spec. Errors
+// are returned as Errors with source.pointer set to the offending member.
+func Decode(r io.Reader, opts DecodeOptions) (*Document, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, Errors{NewBadRequest(err)}
+	}
+
+	top := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &top); err != nil {
+		return nil, Errors{invalidDocument("", "request body must be a JSON object")}
+	}
+
+	raw, ok := top["data"]
+	if !ok {
+		return nil, Errors{invalidDocument("", "data is required")}
+	}
+
+	data, errs := decodeResourceObject(raw, opts)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &Document{Data: data, Meta: top["meta"]}, nil
+}
+
+func decodeResourceObject(raw json.RawMessage, opts DecodeOptions) (*ResourceObject, Errors) {
+	members := map[string]json.RawMessage{}
+	if isNull(raw) || json.Unmarshal(raw, &members) != nil {
+		return nil, Errors{invalidDocument("/data", "data must be a resource object")}
+	}
+
+	obj := &ResourceObject{
+		Attributes:    members["attributes"],
+		Relationships: map[string]RelationshipData{},
+		Meta:          members["meta"],
+	}
+	errs := Errors{}
+
+	if err := decodeString(members, "type", &obj.Type); err != nil || obj.Type == "" {
+		errs = append(errs, invalidDocument("/data/type", "type is required and must be a string"))
+	}
+
+	if err := decodeString(members, "id", &obj.ID); err != nil {
+		errs = append(errs, invalidDocument("/data/id", "id must be a string"))
+	}
+
+	if err := decodeString(members, "lid", &obj.LID); err != nil {
+		errs = append(errs, invalidDocument("/data/lid", "lid must be a string"))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if opts.Type != "" && obj.Type != opts.Type {
+		errs = append(errs, conflict("/data/type", fmt.Sprintf("type %s does not match the endpoint type %s", obj.Type, opts.Type)))
+	}
+
+	switch {
+	case opts.ID != "" && obj.ID == "":
+		errs = append(errs, invalidDocument("/data/id", "id is required"))
+	case opts.ID != "" && obj.ID != opts.ID:
+		errs = append(errs, conflict("/data/id", fmt.Sprintf("id %q does not match the resource id %q", obj.ID, opts.ID)))
+	case opts.ID == "" && obj.ID != "" && !opts.AllowClientID:
+		e := NewForbidden(fmt.Errorf("client-generated ids are not supported"))
+		e.Source = &ErrorSource{Pointer: "/data/id"}
+		errs = append(errs, e)
+	}
+
+	errs = append(errs, obj.validateAttributes()...)
+	errs = append(errs, obj.decodeRelationships(members["relationships"])...)
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return obj, nil
+}
+
+func (r *ResourceObject) validateAttributes() Errors {
+	if r.Attributes == nil {
+		return nil
+	}
+
+	errs := Errors{}
+	err := eachMember(string(r.Attributes), func(key string, _ json.RawMessage) error {
+		switch key {
+		case "id", "type", "relationships", "links":
+			errs = append(errs, invalidDocument("/data/attributes/"+key, fmt.Sprintf("attributes must not have %s", key)))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Errors{invalidDocument("/data/attributes", "attributes must be an object")}
+	}
+
+	return errs
+}
+
+func (r *ResourceObject) decodeRelationships(raw json.RawMessage) Errors {
+	if raw == nil {
+		return nil
+	}
+
+	relationships := map[string]json.RawMessage{}
+	if json.Unmarshal(raw, &relationships) != nil {
+		return Errors{invalidDocument("/data/relationships", "relationships must be an object")}
+	}
+
+	names := make([]string, 0, len(relationships))
+	for name := range relationships {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := Errors{}
+	for _, name := range names {
+		v := relationships[name]
+		pointer := "/data/relationships/" + escapePointer(name)
+
+		members := map[string]json.RawMessage{}
+		if json.Unmarshal(v, &members) != nil {
+			errs = append(errs, invalidDocument(pointer, "relationship must be an object"))
+			continue
+		}
+
+		data, ok := members["data"]
+		if !ok {
+			errs = append(errs, invalidDocument(pointer, "relationship must have data"))
+			continue
+		}
+
+		rel, derrs := decodeLinkage(data, pointer+"/data")
+		if len(derrs) > 0 {
+			errs = append(errs, derrs...)
+			continue
+		}
+
+		r.Relationships[name] = rel
+	}
+
+	return errs
+}
+
+func decodeLinkage(raw json.RawMessage, pointer string) (RelationshipData, Errors) {
+	if isNull(raw) {
+		return RelationshipData{}, nil
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		list := []json.RawMessage{}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return RelationshipData{}, Errors{invalidDocument(pointer, "data must be an array of resource identifiers")}
+		}
+
+		rel := RelationshipData{Many: true, Data: []ResourceIdentifier{}}
+		errs := Errors{}
+		for i, item := range list {
+			ri, err := decodeIdentifier(item, pointer+"/"+strconv.Itoa(i))
+			if err != nil {
+				errs = append(errs, *err)
+				continue
+			}
+			rel.Data = append(rel.Data, ri)
+		}
+
+		return rel, errs
+	}
+
+	ri, err := decodeIdentifier(raw, pointer)
+	if err != nil {
+		return RelationshipData{}, Errors{*err}
+	}
+
+	return RelationshipData{Data: []ResourceIdentifier{ri}}, nil
+}
+
+func decodeIdentifier(raw json.RawMessage, pointer string) (ResourceIdentifier, *Error) {
+	members := map[string]json.RawMessage{}
+	if json.Unmarshal(raw, &members) != nil {
+		e := invalidDocument(pointer, "resource identifier must be an object")
+		return ResourceIdentifier{}, &e
+	}
+
+	ri := ResourceIdentifier{}
+	if err := decodeString(members, "type", &ri.Type); err != nil || ri.Type == "" {
+		e := invalidDocument(pointer+"/type", "type is required and must be a string")
+		return ri, &e
+	}
+
+	if err := decodeString(members, "id", &ri.ID); err != nil {
+		e := invalidDocument(pointer+"/id", "id must be a string")
+		return ri, &e
+	}
+
+	if err := decodeString(members, "lid", &ri.LID); err != nil {
+		e := invalidDocument(pointer+"/lid", "lid must be a string")
+		return ri, &e
+	}
+
+	if ri.ID == "" && ri.LID == "" {
+		e := invalidDocument(pointer+"/id", "id or lid is required")
+		return ri, &e
+	}
+
+	return ri, nil
+}
+
+// Unmarshal decodes the attributes into v. Type mismatches are reported with
+// the pointer of the attribute.
+func (r ResourceObject) Unmarshal(v any) error {
+	if r.Attributes == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(r.Attributes, v); err != nil {
+		pointer := "/data/attributes"
+		if ute, ok := err.(*json.UnmarshalTypeError); ok && ute.Field != "" {
+			pointer += "/" + strings.ReplaceAll(ute.Field, ".", "/")
+		}
+
+		return Errors{invalidDocument(pointer, err.Error())}
+	}
+
+	return nil
+}
+
+// Relationship returns the to-one linkage of name. ok is false when the
+// relationship is absent or to-many, and the identifier is nil for null.
+func (r ResourceObject) Relationship(name string) (*ResourceIdentifier, bool) {
+	rel, ok := r.Relationships[name]
+	if !ok || rel.Many {
+		return nil, false
+	}
+
+	if len(rel.Data) == 0 {
+		return nil, true
+	}
+
+	return &rel.Data[0], true
+}
+
+// RelationshipMany returns the to-many linkage of name.
+func (r ResourceObject) RelationshipMany(name string) ([]ResourceIdentifier, bool) {
+	rel, ok := r.Relationships[name]
+	if !ok || !rel.Many {
+		return nil, false
+	}
+
+	return rel.Data, true
+}
+
+func decodeString(members map[string]json.RawMessage, key string, dst *string) error {
+	v, ok := members[key]
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(v, dst)
+}
+
+func isNull(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "null"
+}
+
+// escapePointer escapes a member name as a JSON pointer token.
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func invalidDocument(pointer, detail string) Error {
+	e := NewBadRequest(fmt.Errorf("%s", detail))
+	e.Code = "invalid_document"
+	if pointer != "" {
+		e.Source = &ErrorSource{Pointer: pointer}
+	}
+
+	return e
+}
+
+func conflict(pointer, detail string) Error {
+	e := NewConflict(fmt.Errorf("%s", detail))
+	e.Source = &ErrorSource{Pointer: pointer}
+
+	return e
+}
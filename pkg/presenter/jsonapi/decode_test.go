@@ -0,0 +1,108 @@
+package jsonapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	body := `{
+		"data": {
+			"type": "user",
+			"attributes": { "username": "gooo", "email": "gooo@example.com" },
+			"relationships": {
+				"profile": { "data": { "type": "profile", "id": "1" } },
+				"post": { "data": [{ "type": "post", "id": "2" }, { "type": "post", "lid": "local-1" }] }
+			}
+		}
+	}`
+
+	doc, err := Decode(strings.NewReader(body), DecodeOptions{Type: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := queryUser{}
+	if err := doc.Data.Unmarshal(&u); err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := (queryUser{Username: "gooo", Email: "gooo@example.com"}); u != expect {
+		t.Errorf("expect %+v, got %+v", expect, u)
+	}
+
+	profile, ok := doc.Data.Relationship("profile")
+	if !ok || *profile != (ResourceIdentifier{ID: "1", Type: "profile"}) {
+		t.Errorf("unexpected profile %v", profile)
+	}
+
+	posts, ok := doc.Data.RelationshipMany("post")
+	if expect := []ResourceIdentifier{{ID: "2", Type: "post"}, {LID: "local-1", Type: "post"}}; !ok || !reflect.DeepEqual(posts, expect) {
+		t.Errorf("expect %v, got %v", expect, posts)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		opts    DecodeOptions
+		status  int
+		pointer []string
+	}{
+		{name: "missing data", body: `{}`, status: 400, pointer: []string{""}},
+		{name: "missing type", body: `{"data": {"attributes": {}}}`, status: 400, pointer: []string{"/data/type"}},
+		{name: "type conflict", body: `{"data": {"type": "post"}}`, opts: DecodeOptions{Type: "user"}, status: 409, pointer: []string{"/data/type"}},
+		{name: "client id", body: `{"data": {"type": "user", "id": "1"}}`, opts: DecodeOptions{Type: "user"}, status: 403, pointer: []string{"/data/id"}},
+		{name: "missing id", body: `{"data": {"type": "user"}}`, opts: DecodeOptions{Type: "user", ID: "1"}, status: 400, pointer: []string{"/data/id"}},
+		{name: "id conflict", body: `{"data": {"type": "user", "id": "2"}}`, opts: DecodeOptions{Type: "user", ID: "1"}, status: 409, pointer: []string{"/data/id"}},
+		{name: "forbidden attribute", body: `{"data": {"type": "user", "attributes": {"type": "admin"}}}`, status: 400, pointer: []string{"/data/attributes/type"}},
+		{
+			name:    "linkage",
+			body:    `{"data": {"type": "user", "relationships": {"post": {"data": [{"type": "post"}]}, "profile": {}}}}`,
+			status:  400,
+			pointer: []string{"/data/relationships/post/data/0/id", "/data/relationships/profile"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(test.body), test.opts)
+			errs, ok := err.(Errors)
+			if !ok {
+				t.Fatalf("expected Errors, got %T: %v", err, err)
+			}
+
+			if errs.HTTPStatus() != test.status {
+				t.Errorf("expect status %d, got %d", test.status, errs.HTTPStatus())
+			}
+
+			pointers := []string{}
+			for _, e := range errs {
+				p := ""
+				if e.Source != nil {
+					p = e.Source.Pointer
+				}
+				pointers = append(pointers, p)
+			}
+
+			if !reflect.DeepEqual(pointers, test.pointer) {
+				t.Errorf("expect %v, got %v", test.pointer, pointers)
+			}
+		})
+	}
+}
+
+func TestResourceObjectUnmarshalError(t *testing.T) {
+	doc, err := Decode(strings.NewReader(`{"data": {"type": "user", "attributes": {"email": 1}}}`), DecodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = doc.Data.Unmarshal(&queryUser{})
+	errs, ok := err.(Errors)
+	if !ok || errs[0].Source.Pointer != "/data/attributes/email" {
+		t.Errorf("expected pointer to the attribute, got %v", err)
+	}
+}
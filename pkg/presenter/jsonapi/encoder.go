@@ -0,0 +1,478 @@
+package jsonapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+)
+
+// Encoder writes compact JSON:API documents to an io.Writer without building
+// the whole document in memory.
+type Encoder struct {
+	w       *bufio.Writer
+	compact bytes.Buffer
+	num     []byte
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// encodable is implemented by the types of this package, which write
+// themselves directly. Other serializers are compacted from their output.
+type encodable interface {
+	encode(e *Encoder) error
+}
+
+// Encode writes s and flushes the buffered output.
+func (e *Encoder) Encode(s Serializer) error {
+	if err := e.value(s); err != nil {
+		return err
+	}
+
+	if err := e.w.Flush(); err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (e *Encoder) flush(v encodable) error {
+	if err := v.encode(e); err != nil {
+		return err
+	}
+
+	if err := e.w.Flush(); err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (e *Encoder) value(s Serializer) error {
+	if v, ok := s.(encodable); ok {
+		return v.encode(e)
+	}
+
+	str, err := s.JSONAPISerialize()
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	return e.raw([]byte(str))
+}
+
+// raw writes a JSON value with insignificant spaces removed.
+func (e *Encoder) raw(b []byte) error {
+	e.compact.Reset()
+	if err := json.Compact(&e.compact, b); err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	_, err := e.w.Write(e.compact.Bytes())
+	return err
+}
+
+func (e *Encoder) key(k string) {
+	e.string(k)
+	e.w.WriteByte(':')
+}
+
+func (e *Encoder) int(i int) {
+	e.num = strconv.AppendInt(e.num[:0], int64(i), 10)
+	e.w.Write(e.num)
+}
+
+const hex = "0123456789abcdef"
+
+// string writes s quoted the same way as encoding/json, including the HTML
+// escaping.
+func (e *Encoder) string(s string) {
+	e.w.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+
+			e.w.WriteString(s[start:i])
+			switch b {
+			case '\\', '"':
+				e.w.WriteByte('\\')
+				e.w.WriteByte(b)
+			case '\b':
+				e.w.WriteString(`\b`)
+			case '\f':
+				e.w.WriteString(`\f`)
+			case '\n':
+				e.w.WriteString(`\n`)
+			case '\r':
+				e.w.WriteString(`\r`)
+			case '\t':
+				e.w.WriteString(`\t`)
+			default:
+				e.w.WriteString(`\u00`)
+				e.w.WriteByte(hex[b>>4])
+				e.w.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			e.w.WriteString(s[start:i])
+			e.w.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+
+		if c == '\u2028' || c == '\u2029' {
+			e.w.WriteString(s[start:i])
+			e.w.WriteString(`\u202`)
+			e.w.WriteByte(hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+	e.w.WriteString(s[start:])
+	e.w.WriteByte('"')
+}
+
+func encodeString(v encodable) (string, error) {
+	sb := &strings.Builder{}
+	if err := NewEncoder(sb).flush(v); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+func (j Root[T]) encode(e *Encoder) error {
+	e.w.WriteByte('{')
+	if j.JSONAPI != nil {
+		e.key("jsonapi")
+		if err := j.JSONAPI.encode(e); err != nil {
+			return err
+		}
+		e.w.WriteByte(',')
+	}
+
+	e.key("data")
+	if err := e.value(j.Data); err != nil {
+		return err
+	}
+
+	if j.Meta != nil {
+		e.w.WriteString(`,"meta":`)
+		if err := e.value(j.Meta); err != nil {
+			return err
+		}
+	}
+
+	if j.Links != nil {
+		e.w.WriteString(`,"links":`)
+		if err := e.value(j.Links); err != nil {
+			return err
+		}
+	}
+
+	if len(j.Errors) > 0 {
+		e.w.WriteString(`,"errors":`)
+		if err := j.Errors.encode(e); err != nil {
+			return err
+		}
+	}
+
+	if len(j.Included.Data) > 0 {
+		e.w.WriteString(`,"included":`)
+		if err := j.Included.encode(e); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte('}')
+}
+
+func (s Serializers) encode(e *Encoder) error {
+	e.w.WriteByte('[')
+	for i, it := range s {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+
+		if err := e.value(it); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte(']')
+}
+
+func (a Attributes[T]) encode(e *Encoder) error {
+	b, err := json.Marshal(a.v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (j Resources) encode(e *Encoder) error {
+	e.w.WriteByte('[')
+	for i, r := range j.Data {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+
+		if err := r.encode(e); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte(']')
+}
+
+func (j Resource) encode(e *Encoder) error {
+	e.w.WriteByte('{')
+	e.key("id")
+	e.string(j.ID)
+	e.w.WriteByte(',')
+	e.key("type")
+	e.string(j.Type)
+
+	if j.Attributes != nil {
+		e.w.WriteByte(',')
+		e.key("attributes")
+		if err := e.value(j.Attributes); err != nil {
+			return goooerrors.Wrap(err)
+		}
+	}
+
+	if len(j.Relationships) > 0 {
+		e.w.WriteByte(',')
+		e.key("relationships")
+		if err := j.Relationships.encode(e); err != nil {
+			return err
+		}
+	}
+
+	if err := e.linksAndMeta(j.Links, j.Meta); err != nil {
+		return err
+	}
+
+	return e.w.WriteByte('}')
+}
+
+// encode writes the relationships in the order of their names.
+func (j Relationships) encode(e *Encoder) error {
+	keys := make([]string, 0, len(j))
+	for k := range j {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	e.w.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+
+		e.key(k)
+		if err := e.value(j[k]); err != nil {
+			return goooerrors.Wrap(err)
+		}
+	}
+
+	return e.w.WriteByte('}')
+}
+
+func (j RelationshipHasMany) encode(e *Encoder) error {
+	e.w.WriteString(`{"data":[`)
+	for i, ri := range j.Data {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+
+		ri.encode(e)
+	}
+	e.w.WriteByte(']')
+
+	if err := e.linksAndMeta(j.Links, j.Meta); err != nil {
+		return err
+	}
+
+	return e.w.WriteByte('}')
+}
+
+func (j Relationship) encode(e *Encoder) error {
+	e.w.WriteString(`{"data":`)
+	j.Data.encode(e)
+
+	if err := e.linksAndMeta(j.Links, j.Meta); err != nil {
+		return err
+	}
+
+	return e.w.WriteByte('}')
+}
+
+// linksAndMeta writes the optional links and meta members of an object.
+func (e *Encoder) linksAndMeta(links *Links, meta Serializer) error {
+	if links != nil {
+		e.w.WriteByte(',')
+		e.key("links")
+		links.encode(e)
+	}
+
+	if meta != nil {
+		e.w.WriteByte(',')
+		e.key("meta")
+		if err := e.value(meta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o Object) encode(e *Encoder) error {
+	e.w.WriteByte('{')
+	e.key("version")
+	e.string(o.Version)
+
+	if o.Meta != nil {
+		e.w.WriteByte(',')
+		e.key("meta")
+		if err := e.value(o.Meta); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte('}')
+}
+
+func (j ResourceIdentifier) encode(e *Encoder) error {
+	e.w.WriteByte('{')
+	if j.ID == "" && j.LID != "" {
+		e.key("lid")
+		e.string(j.LID)
+	} else {
+		e.key("id")
+		e.string(j.ID)
+	}
+	e.w.WriteByte(',')
+	e.key("type")
+	e.string(j.Type)
+	return e.w.WriteByte('}')
+}
+
+func (n Nil) encode(e *Encoder) error {
+	_, err := e.w.WriteString("null")
+	return err
+}
+
+func (j Errors) encode(e *Encoder) error {
+	e.w.WriteByte('[')
+	for i, it := range j {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+
+		if err := it.encode(e); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte(']')
+}
+
+func (j Error) encode(e *Encoder) error {
+	e.w.WriteByte('{')
+	e.key("id")
+	e.string(j.ID)
+	e.w.WriteByte(',')
+	e.key("status")
+	e.int(j.Status)
+	e.w.WriteByte(',')
+	e.key("code")
+	e.string(j.Code)
+	e.w.WriteByte(',')
+	e.key("title")
+	e.string(j.Title)
+	e.w.WriteByte(',')
+	e.key("detail")
+	e.string(j.Detail)
+
+	if j.Source != nil {
+		e.w.WriteByte(',')
+		e.key("source")
+		if err := j.Source.encode(e); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte('}')
+}
+
+func (s ErrorSource) encode(e *Encoder) error {
+	e.members([]member{
+		{"pointer", s.Pointer},
+		{"parameter", s.Parameter},
+		{"header", s.Header},
+	})
+
+	return nil
+}
+
+func (l Links) encode(e *Encoder) error {
+	e.members([]member{
+		{"self", l.Self},
+		{"related", l.Related},
+		{"first", l.First},
+		{"prev", l.Prev},
+		{"next", l.Next},
+		{"last", l.Last},
+	})
+
+	return nil
+}
+
+type member struct {
+	key   string
+	value string
+}
+
+// members writes an object of the non-empty string members.
+func (e *Encoder) members(list []member) {
+	e.w.WriteByte('{')
+	first := true
+	for _, m := range list {
+		if m.value == "" {
+			continue
+		}
+
+		if !first {
+			e.w.WriteByte(',')
+		}
+		first = false
+
+		e.key(m.key)
+		e.string(m.value)
+	}
+	e.w.WriteByte('}')
+}
@@ -0,0 +1,111 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestEncoderString(t *testing.T) {
+	for _, s := range []string{
+		"plain",
+		"\"quoted\" \\ back",
+		"<b>tag</b> & amp",
+		"\b\f\n\r\t\x00\x1f",
+		"line para ",
+		"日本語",
+	} {
+		expect, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := &bytes.Buffer{}
+		e := NewEncoder(buf)
+		e.string(s)
+		if err := e.w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != string(expect) {
+			t.Errorf("expect %s, got %s", expect, buf.String())
+		}
+	}
+}
+
+type encoderCustomMeta struct{}
+
+func (encoderCustomMeta) JSONAPISerialize() (string, error) {
+	return `{ "total": 1 }`, nil
+}
+
+func TestRootEncode(t *testing.T) {
+	root, err := New(Resource{
+		ID:         "1",
+		Type:       "user",
+		Attributes: NewAttributes(map[string]string{"name": "gooo"}),
+		Relationships: Relationships{
+			"profile": Relationship{Data: ResourceIdentifier{ID: "2", Type: "profile"}},
+			"post":    RelationshipHasMany{Data: []ResourceIdentifier{{ID: "3", Type: "post"}}},
+		},
+	}, Resources{}, encoderCustomMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := root.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"data":{"id":"1","type":"user","attributes":{"name":"gooo"},"relationships":{"post":{"data":[{"id":"3","type":"post"}]},"profile":{"data":{"id":"2","type":"profile"}}}},"meta":{"total":1}}`
+	if buf.String() != expect {
+		t.Errorf("expect %s, got %s", expect, buf.String())
+	}
+
+	s, err := root.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != expect {
+		t.Errorf("expect Serialize to match Encode, got %s", s)
+	}
+}
+
+func TestRootEncodeLinksAndMeta(t *testing.T) {
+	root, err := New(Resource{
+		ID:         "1",
+		Type:       "user",
+		Attributes: NewAttributes(map[string]string{"name": "gooo"}),
+		Relationships: Relationships{
+			"profile": Relationship{
+				Data:  ResourceIdentifier{ID: "2", Type: "profile"},
+				Links: &Links{Self: "/users/1/relationships/profile", Related: "/users/1/profile"},
+			},
+			"post": RelationshipHasMany{
+				Data: []ResourceIdentifier{},
+				Meta: encoderCustomMeta{},
+			},
+		},
+		Links: &Links{Self: "/users/1"},
+		Meta:  NewAttributes(map[string]bool{"active": true}),
+	}, Resources{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.JSONAPI = NewObject()
+
+	s, err := root.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"jsonapi":{"version":"1.1"},"data":{"id":"1","type":"user","attributes":{"name":"gooo"},` +
+		`"relationships":{"post":{"data":[],"meta":{"total":1}},` +
+		`"profile":{"data":{"id":"2","type":"profile"},"links":{"self":"/users/1/relationships/profile","related":"/users/1/profile"}}},` +
+		`"links":{"self":"/users/1"},"meta":{"active":true}}}`
+	if s != expect {
+		t.Errorf("expect %s, got %s", expect, s)
+	}
+}
@@ -2,6 +2,7 @@ package jsonapi
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -16,23 +17,30 @@ func (j Errors) Error() string {
 	return fmt.Sprintf("[%s]", strings.Join(list, ", "))
 }
 
-func (j Errors) JSONAPISerialize() (string, error) {
-	str := "["
-	for i, e := range j {
-		json, err := e.JSONAPISerialize()
-		if err != nil {
-			return "", err
+// HTTPStatus returns the common status of the errors. Mixed statuses fall
+// back to 400 or 500 by their class.
+func (j Errors) HTTPStatus() int {
+	status := 0
+	for _, e := range j {
+		switch {
+		case status == 0 || status == e.Status:
+			status = e.Status
+		case status < 500 && e.Status < 500:
+			status = http.StatusBadRequest
+		default:
+			return http.StatusInternalServerError
 		}
+	}
 
-		comma := ""
-		if i != len(j)-1 {
-			comma = ","
-		}
-		str += json + comma
+	if status == 0 {
+		return http.StatusInternalServerError
 	}
-	str += "]"
 
-	return str, nil
+	return status
+}
+
+func (j Errors) JSONAPISerialize() (string, error) {
+	return encodeString(j)
 }
 
 type Error struct {
@@ -41,6 +49,18 @@ type Error struct {
 	Code   string
 	Title  string
 	Detail string
+	Source *ErrorSource
+}
+
+// ErrorSource points to the part of the request that caused the error.
+type ErrorSource struct {
+	Pointer   string
+	Parameter string
+	Header    string
+}
+
+func (s ErrorSource) JSONAPISerialize() (string, error) {
+	return encodeString(s)
 }
 
 func (j Error) Error() string {
@@ -48,40 +68,15 @@ func (j Error) Error() string {
 }
 
 func (j Error) JSONAPISerialize() (string, error) {
-	id, err := Escape(j.ID)
-	if err != nil {
-		return "", err
-	}
-
-	status, err := Escape(j.Status)
-	if err != nil {
-		return "", err
-	}
-
-	code, err := Escape(j.Code)
-	if err != nil {
-		return "", err
-	}
-
-	title, err := Escape(j.Title)
-	if err != nil {
-		return "", err
-	}
-
-	detail, err := Escape(j.Detail)
-	if err != nil {
-		return "", err
-	}
+	return encodeString(j)
+}
 
-	fields := []string{
-		fmt.Sprintf("\"id\": %s", id),
-		fmt.Sprintf("\"status\": %s", status),
-		fmt.Sprintf("\"code\": %s", code),
-		fmt.Sprintf("\"title\": %s", title),
-		fmt.Sprintf("\"detail\": %s", detail),
+func (j Error) HTTPStatus() int {
+	if j.Status == 0 {
+		return http.StatusInternalServerError
 	}
 
-	return fmt.Sprintf("{\n%s\n}", strings.Join(fields, ", \n")), nil
+	return j.Status
 }
 
 type Errable interface {
@@ -1,6 +1,7 @@
 package jsonapi
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -14,6 +15,14 @@ type TitleGetter interface {
 	Title() string
 }
 
+// StatusGetter is implemented by errors that know their HTTP status.
+type StatusGetter interface {
+	HTTPStatus() int
+}
+
+var _ StatusGetter = Error{}
+var _ StatusGetter = Errors{}
+
 var _ Errable = ErrorResponse{}
 
 type ErrorResponse struct {
@@ -24,8 +33,10 @@ func NewErrorResponse(err error) *ErrorResponse {
 	return &ErrorResponse{err}
 }
 
+// Code, Title and the status are taken from the error or any error it wraps.
 func (e ErrorResponse) Code() string {
-	if c, ok := e.err.(CodeGetter); ok {
+	var c CodeGetter
+	if errors.As(e.err, &c) {
 		return c.Code()
 	}
 
@@ -33,8 +44,9 @@ func (e ErrorResponse) Code() string {
 }
 
 func (e ErrorResponse) Title() string {
-	if c, ok := e.err.(TitleGetter); ok {
-		return c.Title()
+	var t TitleGetter
+	if errors.As(e.err, &t) {
+		return t.Title()
 	}
 
 	return "Internal Server Error"
@@ -45,15 +57,31 @@ func (e ErrorResponse) Error() string {
 }
 
 func (e ErrorResponse) ToJSONAPIError() Error {
+	status := http.StatusInternalServerError
+	var sg StatusGetter
+	if errors.As(e.err, &sg) {
+		status = sg.HTTPStatus()
+	}
+
 	return Error{
 		ID:     uuid.New().String(),
 		Title:  e.Title(),
-		Status: http.StatusInternalServerError,
+		Status: status,
 		Code:   e.Code(),
 		Detail: e.Error(),
 	}
 }
 
+func hasCode(err error) bool {
+	var c CodeGetter
+	return errors.As(err, &c)
+}
+
+func hasTitle(err error) bool {
+	var t TitleGetter
+	return errors.As(err, &t)
+}
+
 func NewInternalServerError(err error) Errable {
 	return ErrorResponse{err}
 }
@@ -61,11 +89,11 @@ func NewInternalServerError(err error) Errable {
 func NewBadRequest(err error) Error {
 	e := ErrorResponse{err}.ToJSONAPIError()
 	e.Status = http.StatusBadRequest
-	if _, ok := err.(CodeGetter); !ok {
+	if !hasCode(err) {
 		e.Code = "bad_request"
 	}
 
-	if _, ok := err.(TitleGetter); !ok {
+	if !hasTitle(err) {
 		e.Title = "Bad Request"
 	}
 
@@ -75,11 +103,11 @@ func NewBadRequest(err error) Error {
 func NewUnauthorized(err error) Error {
 	e := ErrorResponse{err}.ToJSONAPIError()
 	e.Status = http.StatusUnauthorized
-	if _, ok := err.(CodeGetter); !ok {
+	if !hasCode(err) {
 		e.Code = "unauthorized"
 	}
 
-	if _, ok := err.(TitleGetter); !ok {
+	if !hasTitle(err) {
 		e.Title = "Unauthorized"
 	}
 
@@ -89,11 +117,11 @@ func NewUnauthorized(err error) Error {
 func NewNotFound(err error) Error {
 	e := ErrorResponse{err}.ToJSONAPIError()
 	e.Status = http.StatusNotFound
-	if _, ok := err.(CodeGetter); !ok {
+	if !hasCode(err) {
 		e.Code = "not_found"
 	}
 
-	if _, ok := err.(TitleGetter); !ok {
+	if !hasTitle(err) {
 		e.Title = "Not Found"
 	}
 
@@ -103,17 +131,59 @@ func NewNotFound(err error) Error {
 func NewForbidden(err error) Error {
 	e := ErrorResponse{err}.ToJSONAPIError()
 	e.Status = http.StatusForbidden
-	if _, ok := err.(CodeGetter); !ok {
+	if !hasCode(err) {
 		e.Code = "forbidden"
 	}
 
-	if _, ok := err.(TitleGetter); !ok {
+	if !hasTitle(err) {
 		e.Title = "Forbidden"
 	}
 
 	return e
 }
 
+func NewConflict(err error) Error {
+	e := ErrorResponse{err}.ToJSONAPIError()
+	e.Status = http.StatusConflict
+	if !hasCode(err) {
+		e.Code = "conflict"
+	}
+
+	if !hasTitle(err) {
+		e.Title = "Conflict"
+	}
+
+	return e
+}
+
+func NewNotAcceptable(err error) Error {
+	e := ErrorResponse{err}.ToJSONAPIError()
+	e.Status = http.StatusNotAcceptable
+	if !hasCode(err) {
+		e.Code = "not_acceptable"
+	}
+
+	if !hasTitle(err) {
+		e.Title = "Not Acceptable"
+	}
+
+	return e
+}
+
+func NewUnprocessableEntity(err error) Error {
+	e := ErrorResponse{err}.ToJSONAPIError()
+	e.Status = http.StatusUnprocessableEntity
+	if !hasCode(err) {
+		e.Code = "unprocessable_entity"
+	}
+
+	if !hasTitle(err) {
+		e.Title = "Unprocessable Entity"
+	}
+
+	return e
+}
+
 type Resourcers []Resourcer
 
 func (r Resourcers) ToJSONAPIResource() (Resources, Resources) {
@@ -1,14 +1,12 @@
 package jsonapi
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
-	"strings"
 
 	goooerrors "github.com/version-1/gooo/pkg/errors"
-	"github.com/version-1/gooo/pkg/logger"
 )
 
 type Resourcer interface {
@@ -19,7 +17,28 @@ type Root[T Serializer] struct {
 	Data     T
 	Errors   Errors
 	Meta     Serializer
+	Links    Serializer
 	Included Resources
+	// JSONAPI is the top level jsonapi object. It is omitted when nil.
+	JSONAPI *Object
+}
+
+// Version is the version of the spec the documents follow.
+const Version = "1.1"
+
+// Object is the jsonapi object describing the server implementation.
+type Object struct {
+	Version string
+	Meta    Serializer
+}
+
+// NewObject returns the jsonapi object of Version.
+func NewObject() *Object {
+	return &Object{Version: Version}
+}
+
+func (o Object) JSONAPISerialize() (string, error) {
+	return encodeString(o)
 }
 
 func New(data Resource, includes Resources, meta Serializer) (*Root[Resource], error) {
@@ -67,50 +86,12 @@ func NewErrors(errors Errors) *Root[Nil] {
 }
 
 func (j Root[T]) Serialize() (string, error) {
-	fields := []string{}
-
-	data, err := j.Data.JSONAPISerialize()
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-	fields = append(fields, fmt.Sprintf("\"data\": %s", data))
-
-	if j.Meta != nil {
-		meta, err := j.Meta.JSONAPISerialize()
-		if err != nil {
-			return "", goooerrors.Wrap(err)
-		}
-		fields = append(fields, fmt.Sprintf("\"meta\": %s", meta))
-	}
-
-	errors, err := j.Errors.JSONAPISerialize()
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-
-	if errors != "[]" {
-		fields = append(fields, fmt.Sprintf("\"errors\": %s", errors))
-	}
-
-	included, err := j.Included.JSONAPISerialize()
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-
-	if included != "[]" {
-		fields = append(fields, fmt.Sprintf("\"included\": %s", included))
-	}
-
-	s := fmt.Sprintf("{\n%s\n}", strings.Join(fields, ", \n"))
-
-	var out bytes.Buffer
-	if err := json.Compact(&out, []byte(s)); err != nil {
-		logger.DefaultLogger.Errorf("pkg/presenter/jsonapi: got error on compact json. %s")
-		logger.DefaultLogger.Errorf("%s\n", s)
-		return "", goooerrors.Wrap(err)
-	}
+	return encodeString(j)
+}
 
-	return out.String(), nil
+// Encode writes the document to w as compact JSON.
+func (j Root[T]) Encode(w io.Writer) error {
+	return NewEncoder(w).flush(j)
 }
 
 var _ Serializer = Resource{}
@@ -126,21 +107,7 @@ type Serializer interface {
 type Serializers []Serializer
 
 func (s Serializers) JSONAPISerialize() (string, error) {
-	str := "["
-	for i, serializer := range s {
-		json, err := serializer.JSONAPISerialize()
-		if err != nil {
-			return "", goooerrors.Wrap(err)
-		}
-
-		comma := ""
-		if i != len(s)-1 {
-			comma = ","
-		}
-		str += json + comma
-	}
-	str += "]"
-	return str, nil
+	return encodeString(s)
 }
 
 type Attributes[T any] struct {
@@ -196,19 +163,7 @@ func (j *Resources) Append(r ...Resource) {
 }
 
 func (j Resources) JSONAPISerialize() (string, error) {
-	lines := []string{}
-	for _, r := range j.Data {
-		json, err := r.JSONAPISerialize()
-		if err != nil {
-			return "", err
-		}
-		lines = append(lines, json)
-	}
-
-	str := "["
-	str += strings.Join(lines, ", \n")
-	str += "]"
-	return str, nil
+	return encodeString(j)
 }
 
 type Resource struct {
@@ -216,126 +171,52 @@ type Resource struct {
 	Type          string
 	Attributes    Serializer
 	Relationships Relationships
+	// Links usually has the self link of the resource.
+	Links *Links
+	Meta  Serializer
 }
 
 func (j Resource) JSONAPISerialize() (string, error) {
-	attrs, err := j.Attributes.JSONAPISerialize()
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-
-	r, err := j.Relationships.JSONAPISerialize()
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-
-	list := []string{}
-
-	id, err := Escape(j.ID)
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-
-	t, err := Escape(j.Type)
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-	list = append(list, fmt.Sprintf(`"id": %s`, id))
-	list = append(list, fmt.Sprintf(`"type": %s`, t))
-	list = append(list, fmt.Sprintf(`"attributes": %s`, attrs))
-	if r != "{}" {
-		list = append(list, fmt.Sprintf(`"relationships": %s`, r))
-	}
-
-	return "{\n" + strings.Join(list, ", \n") + "\n}", nil
-}
-
-func roundQuotes(s string) string {
-	if len(s) < 1 {
-		return s
-	}
-
-	if s[0] == '"' && s[len(s)-1] == '"' {
-		return s[1 : len(s)-1]
-	}
-
-	return s
+	return encodeString(j)
 }
 
 type Relationships map[string]Serializer
 
 func (j Relationships) JSONAPISerialize() (string, error) {
-	lines := []string{}
-	for k, r := range j {
-		json, err := r.JSONAPISerialize()
-		if err != nil {
-			return "", goooerrors.Wrap(err)
-		}
-		lines = append(lines, "\""+k+"\": "+json)
-	}
-	str := "{"
-	str += strings.Join(lines, ", \n")
-	str += "}"
-	return str, nil
+	return encodeString(j)
 }
 
 type RelationshipHasMany struct {
 	Data []ResourceIdentifier
+	// Links usually has the self and related links of the relationship.
+	Links *Links
+	Meta  Serializer
 }
 
 func (j RelationshipHasMany) JSONAPISerialize() (string, error) {
-	lines := []string{}
-	for i := range j.Data {
-		json, err := j.Data[i].JSONAPISerialize()
-		if err != nil {
-			return "", goooerrors.Wrap(err)
-		}
-
-		lines = append(lines, json)
-	}
-	str := "["
-	str += strings.Join(lines, ", \n")
-	str += "]"
-
-	return `{
-		"data": ` + str + `
-	}`, nil
+	return encodeString(j)
 }
 
 type Relationship struct {
-	Data ResourceIdentifier
+	Data  ResourceIdentifier
+	Links *Links
+	Meta  Serializer
 }
 
 func (j Relationship) JSONAPISerialize() (string, error) {
-	json, err := j.Data.JSONAPISerialize()
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-
-	return `{
-		"data": ` + json + `
-	}`, nil
+	return encodeString(j)
 }
 
+// ResourceIdentifier identifies a resource by ID, or by LID for a resource
+// created in the same request document.
 type ResourceIdentifier struct {
 	ID   string
+	LID  string
 	Type string
 }
 
 func (j ResourceIdentifier) JSONAPISerialize() (string, error) {
-	id, err := Escape(j.ID)
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-
-	t, err := Escape(j.Type)
-	if err != nil {
-		return "", goooerrors.Wrap(err)
-	}
-	return `{
-		"id": ` + id + `,
-		"type": ` + t + `
-	}`, nil
+	return encodeString(j)
 }
 
 type Nil struct{}
@@ -344,12 +225,20 @@ func (n Nil) JSONAPISerialize() (string, error) {
 	return "null", nil
 }
 
+// HasOne sets the linkage of the relationship named after typeName to ele and
+// includes it.
 func HasOne(r *Resource, includes *Resources, ele Resourcer, id any, typeName string) {
+	HasOneNamed(r, includes, typeName, ele, id, typeName)
+}
+
+// HasOneNamed sets the linkage of the relationship name to ele and includes
+// it.
+func HasOneNamed(r *Resource, includes *Resources, name string, ele Resourcer, id any, typeName string) {
 	if ele == nil {
 		return
 	}
 
-	r.Relationships[typeName] = Relationship{
+	r.Relationships[name] = Relationship{
 		Data: ResourceIdentifier{
 			ID:   Stringify(id),
 			Type: typeName,
@@ -361,7 +250,34 @@ func HasOne(r *Resource, includes *Resources, ele Resourcer, id any, typeName st
 	includes.Append(childIncludes.Data...)
 }
 
+// BelongsTo sets the linkage from a foreign key, which is known without
+// loading the associated resource. ele is included unless it is nil.
+func BelongsTo(r *Resource, includes *Resources, name string, ele Resourcer, id any, typeName string) {
+	r.Relationships[name] = Relationship{
+		Data: ResourceIdentifier{
+			ID:   Stringify(id),
+			Type: typeName,
+		},
+	}
+
+	if ele == nil {
+		return
+	}
+
+	resource, childIncludes := ele.ToJSONAPIResource()
+	includes.Append(resource)
+	includes.Append(childIncludes.Data...)
+}
+
+// HasMany sets the linkage of the relationship named after typeName to the
+// elements and includes them. cb fills the identifier of each element.
 func HasMany(r *Resource, includes *Resources, elements []Resourcer, typeName string, cb func(ri *ResourceIdentifier, index int)) {
+	HasManyNamed(r, includes, typeName, elements, typeName, cb)
+}
+
+// HasManyNamed sets the linkage of the relationship name to the elements and
+// includes them. cb fills the identifier of each element.
+func HasManyNamed(r *Resource, includes *Resources, name string, elements []Resourcer, typeName string, cb func(ri *ResourceIdentifier, index int)) {
 	relationships := RelationshipHasMany{}
 	for i, ele := range elements {
 		ri := ResourceIdentifier{
@@ -379,6 +295,6 @@ func HasMany(r *Resource, includes *Resources, elements []Resourcer, typeName st
 	}
 
 	if len(relationships.Data) > 0 {
-		r.Relationships[typeName] = relationships
+		r.Relationships[name] = relationships
 	}
 }
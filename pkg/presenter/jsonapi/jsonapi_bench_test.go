@@ -0,0 +1,72 @@
+package jsonapi
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+type benchArticle struct {
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Views     int       `json:"views"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func benchRoot(n int) *Root[Resources] {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := Resources{}
+	included := Resources{ShouldSort: true}
+	for i := 0; i < n; i++ {
+		data.Append(Resource{
+			ID:   fmt.Sprint(i),
+			Type: "article",
+			Attributes: NewAttributes(benchArticle{
+				Title:     fmt.Sprintf("title %d", i),
+				Body:      "body <b>with</b> \"quotes\"",
+				Views:     i,
+				CreatedAt: now,
+			}),
+			Relationships: Relationships{
+				"author":  Relationship{Data: ResourceIdentifier{ID: fmt.Sprint(i % 10), Type: "user"}},
+				"comment": RelationshipHasMany{Data: []ResourceIdentifier{{ID: fmt.Sprint(i), Type: "comment"}}},
+			},
+		})
+	}
+
+	for i := 0; i < 10; i++ {
+		included.Append(Resource{
+			ID:            fmt.Sprint(i),
+			Type:          "user",
+			Attributes:    NewAttributes(map[string]string{"name": fmt.Sprint("user", i)}),
+			Relationships: Relationships{},
+		})
+	}
+
+	return newMany(data, included, NewAttributes(map[string]int{"total": n}))
+}
+
+func BenchmarkRootSerialize10k(b *testing.B) {
+	root := benchRoot(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s, err := root.Serialize()
+		if err != nil {
+			b.Fatal(err)
+		}
+		io.WriteString(io.Discard, s)
+	}
+}
+
+func BenchmarkRootEncode10k(b *testing.B) {
+	root := benchRoot(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := root.Encode(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
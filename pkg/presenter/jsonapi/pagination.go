@@ -0,0 +1,151 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var _ Serializer = Links{}
+
+// Links is the links object of documents, resources and relationships.
+// Empty links are omitted.
+type Links struct {
+	Self    string
+	Related string
+	First   string
+	Prev    string
+	Next    string
+	Last    string
+}
+
+func (l Links) JSONAPISerialize() (string, error) {
+	return encodeString(l)
+}
+
+// Pagination builds the pagination links and meta of a collection from the
+// request URL with page[number] and page[size].
+type Pagination struct {
+	URL    *url.URL
+	Number int
+	Size   int
+	Total  int
+}
+
+func NewPagination(u *url.URL, page Page, defaultSize, total int) *Pagination {
+	offset, size := page.OffsetLimit(defaultSize)
+	number := 1
+	if size > 0 {
+		number = offset/size + 1
+	}
+
+	return &Pagination{
+		URL:    u,
+		Number: number,
+		Size:   size,
+		Total:  total,
+	}
+}
+
+func (p Pagination) TotalPages() int {
+	if p.Size <= 0 {
+		return 1
+	}
+
+	pages := (p.Total + p.Size - 1) / p.Size
+	if pages < 1 {
+		return 1
+	}
+
+	return pages
+}
+
+func (p Pagination) Links() Links {
+	last := p.TotalPages()
+	links := Links{
+		Self:  p.link(p.Number),
+		First: p.link(1),
+		Last:  p.link(last),
+	}
+
+	if p.Number > 1 {
+		links.Prev = p.link(min(p.Number-1, last))
+	}
+
+	if p.Number < last {
+		links.Next = p.link(p.Number + 1)
+	}
+
+	return links
+}
+
+func (p Pagination) link(number int) string {
+	if p.URL == nil {
+		return ""
+	}
+
+	u := *p.URL
+	q := u.Query()
+	for _, k := range []string{"page[offset]", "page[limit]", "page[cursor]"} {
+		q.Del(k)
+	}
+	q.Set("page[number]", strconv.Itoa(number))
+	q.Set("page[size]", strconv.Itoa(p.Size))
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+func (p Pagination) JSONAPISerialize() (string, error) {
+	return fmt.Sprintf(
+		`{"page": {"number": %d, "size": %d, "total": %d, "total_pages": %d}}`,
+		p.Number,
+		p.Size,
+		p.Total,
+		p.TotalPages(),
+	), nil
+}
+
+// MergedMeta serializes the objects of every serializer as one object. Later
+// keys win over earlier ones.
+type MergedMeta []Serializer
+
+func (m MergedMeta) JSONAPISerialize() (string, error) {
+	keys := []string{}
+	values := map[string]json.RawMessage{}
+	for _, s := range m {
+		if s == nil {
+			continue
+		}
+
+		raw, err := s.JSONAPISerialize()
+		if err != nil {
+			return "", err
+		}
+
+		err = eachMember(raw, func(key string, value json.RawMessage) error {
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = value
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	fields := []string{}
+	for _, k := range keys {
+		key, err := Escape(k)
+		if err != nil {
+			return "", err
+		}
+
+		fields = append(fields, fmt.Sprintf("%s: %s", key, values[k]))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(fields, ", ")), nil
+}
@@ -0,0 +1,347 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	goooerrors "github.com/version-1/gooo/pkg/errors"
+)
+
+// Query is the typed form of the JSON:API query parameters.
+//
+//	?fields[user]=username,email&include=post.like&sort=-created_at
+//	&filter[status]=active&page[number]=2&page[size]=20
+type Query struct {
+	// Fields are the sparse fieldsets by resource type.
+	Fields map[string][]string
+	// Include is the list of relationship paths. nil means the parameter is
+	// absent and every included resource is kept, while an empty slice
+	// includes nothing.
+	Include []string
+	Sort    []SortField
+	// Filter is keyed by the bracketed names joined with dots, e.g.
+	// filter[author][name] is "author.name".
+	Filter map[string]string
+	Page   Page
+}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+type Page struct {
+	Number int
+	Size   int
+	Offset int
+	Limit  int
+	Cursor string
+}
+
+// OffsetLimit resolves page[number]/page[size] or page[offset]/page[limit]
+// into an offset and a limit. size is used when the query has no size.
+func (p Page) OffsetLimit(size int) (int, int) {
+	if p.Limit > 0 || p.Offset > 0 {
+		limit := p.Limit
+		if limit == 0 {
+			limit = size
+		}
+
+		return p.Offset, limit
+	}
+
+	if p.Size > 0 {
+		size = p.Size
+	}
+
+	number := p.Number
+	if number < 1 {
+		number = 1
+	}
+
+	return (number - 1) * size, size
+}
+
+// ParseQuery parses the JSON:API families of values. Malformed values are
+// returned as Errors with 400 and the parameter as the source.
+func ParseQuery(values url.Values) (Query, error) {
+	q := Query{
+		Fields: map[string][]string{},
+		Filter: map[string]string{},
+	}
+
+	errs := Errors{}
+	for key, vs := range values {
+		if len(vs) == 0 {
+			continue
+		}
+		v := vs[0]
+
+		family, names, ok := parseFamily(key)
+		if !ok {
+			errs = append(errs, invalidParameter(key, fmt.Errorf("malformed parameter %s", key)))
+			continue
+		}
+
+		switch family {
+		case "fields":
+			if len(names) != 1 {
+				errs = append(errs, invalidParameter(key, fmt.Errorf("fields must be fields[type]")))
+				continue
+			}
+			q.Fields[names[0]] = splitList(v)
+		case "include":
+			q.Include = splitList(v)
+		case "sort":
+			for _, f := range splitList(v) {
+				q.Sort = append(q.Sort, SortField{Field: strings.TrimPrefix(f, "-"), Desc: strings.HasPrefix(f, "-")})
+			}
+		case "filter":
+			if len(names) == 0 {
+				errs = append(errs, invalidParameter(key, fmt.Errorf("filter must be filter[name]")))
+				continue
+			}
+			q.Filter[strings.Join(names, ".")] = v
+		case "page":
+			if len(names) != 1 {
+				errs = append(errs, invalidParameter(key, fmt.Errorf("page must be page[name]")))
+				continue
+			}
+
+			if err := q.Page.set(names[0], v); err != nil {
+				errs = append(errs, invalidParameter(key, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return q, errs
+	}
+
+	return q, nil
+}
+
+func (p *Page) set(name, v string) error {
+	if name == "cursor" {
+		p.Cursor = v
+		return nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return fmt.Errorf("page[%s] must be a non-negative integer", name)
+	}
+
+	switch name {
+	case "number":
+		p.Number = n
+	case "size":
+		p.Size = n
+	case "offset":
+		p.Offset = n
+	case "limit":
+		p.Limit = n
+	default:
+		return fmt.Errorf("unknown page parameter %s", name)
+	}
+
+	return nil
+}
+
+// parseFamily splits `family[a][b]` into the family and the bracketed names.
+func parseFamily(key string) (string, []string, bool) {
+	i := strings.IndexByte(key, '[')
+	if i < 0 {
+		return key, nil, true
+	}
+
+	family, rest := key[:i], key[i:]
+	names := []string{}
+	for rest != "" {
+		if rest[0] != '[' {
+			return family, nil, false
+		}
+
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return family, nil, false
+		}
+
+		names = append(names, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	return family, names, true
+}
+
+func splitList(v string) []string {
+	list := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
+
+func invalidParameter(key string, err error) Error {
+	e := NewBadRequest(err)
+	e.Code = "invalid_query_parameter"
+	e.Source = &ErrorSource{Parameter: key}
+
+	return e
+}
+
+// Apply trims the resources to the sparse fieldsets and keeps only the
+// included resources reachable through the include paths from data.
+func (q Query) Apply(data []Resource, included Resources) ([]Resource, Resources) {
+	if q.Include != nil {
+		included = q.includedFrom(data, included)
+	}
+
+	trimmed := make([]Resource, len(data))
+	for i, r := range data {
+		trimmed[i] = q.trim(r)
+	}
+
+	res := Resources{ShouldSort: included.ShouldSort}
+	for _, r := range included.Data {
+		res.Append(q.trim(r))
+	}
+
+	return trimmed, res
+}
+
+func (q Query) trim(r Resource) Resource {
+	fields, ok := q.Fields[r.Type]
+	if !ok {
+		return r
+	}
+
+	keep := map[string]bool{}
+	for _, f := range fields {
+		keep[f] = true
+	}
+
+	r.Attributes = sparseAttributes{attributes: r.Attributes, keep: keep}
+	relationships := Relationships{}
+	for k, v := range r.Relationships {
+		if keep[k] {
+			relationships[k] = v
+		}
+	}
+	r.Relationships = relationships
+
+	return r
+}
+
+func (q Query) includedFrom(data []Resource, included Resources) Resources {
+	index := map[string]Resource{}
+	for _, r := range included.Data {
+		index[r.Type+":"+r.ID] = r
+	}
+
+	res := Resources{ShouldSort: included.ShouldSort}
+	for _, path := range q.Include {
+		current := data
+		for _, name := range strings.Split(path, ".") {
+			next := []Resource{}
+			for _, r := range current {
+				for _, ri := range identifiers(r.Relationships[name]) {
+					if child, ok := index[ri.Type+":"+ri.ID]; ok {
+						res.Append(child)
+						next = append(next, child)
+					}
+				}
+			}
+			current = next
+		}
+	}
+
+	return res
+}
+
+func identifiers(s Serializer) []ResourceIdentifier {
+	switch v := s.(type) {
+	case Relationship:
+		return []ResourceIdentifier{v.Data}
+	case RelationshipHasMany:
+		return v.Data
+	default:
+		return nil
+	}
+}
+
+// sparseAttributes keeps the requested keys of the serialized attributes in
+// their original order.
+type sparseAttributes struct {
+	attributes Serializer
+	keep       map[string]bool
+}
+
+func (s sparseAttributes) JSONAPISerialize() (string, error) {
+	raw, err := s.attributes.JSONAPISerialize()
+	if err != nil {
+		return "", err
+	}
+
+	return filterObject(raw, func(key string) bool { return s.keep[key] })
+}
+
+// filterObject re-encodes a JSON object with the keys accepted by keep.
+func filterObject(raw string, keep func(key string) bool) (string, error) {
+	fields := []string{}
+	err := eachMember(raw, func(key string, value json.RawMessage) error {
+		if !keep(key) {
+			return nil
+		}
+
+		k, err := Escape(key)
+		if err != nil {
+			return err
+		}
+
+		fields = append(fields, k+":"+string(value))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "{" + strings.Join(fields, ",") + "}", nil
+}
+
+// eachMember calls fn with the members of a JSON object in their order.
+func eachMember(raw string, fn func(key string, value json.RawMessage) error) error {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return goooerrors.Errorf("expected a JSON object. got %s", raw)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if err := fn(tok.(string), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package jsonapi
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	values, err := url.ParseQuery("fields[user]=username,email&include=post.like,profile&sort=-created_at,username&filter[status]=active&filter[author][name]=gooo&page[number]=2&page[size]=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := ParseQuery(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := Query{
+		Fields:  map[string][]string{"user": {"username", "email"}},
+		Include: []string{"post.like", "profile"},
+		Sort:    []SortField{{Field: "created_at", Desc: true}, {Field: "username"}},
+		Filter:  map[string]string{"status": "active", "author.name": "gooo"},
+		Page:    Page{Number: 2, Size: 10},
+	}
+	if !reflect.DeepEqual(q, expect) {
+		t.Errorf("expect %+v, got %+v", expect, q)
+	}
+
+	_, err = ParseQuery(url.Values{"page[size]": {"ten"}})
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 1 || errs[0].Source.Parameter != "page[size]" || errs.HTTPStatus() != 400 {
+		t.Errorf("expected a 400 error for page[size], got %v", err)
+	}
+}
+
+type queryUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Bio      string `json:"bio"`
+}
+
+func TestQueryApply(t *testing.T) {
+	user := Resource{
+		ID:         "1",
+		Type:       "user",
+		Attributes: NewAttributes(queryUser{Username: "gooo", Email: "gooo@example.com", Bio: "hello"}),
+		Relationships: Relationships{
+			"posts":   RelationshipHasMany{Data: []ResourceIdentifier{{ID: "10", Type: "post"}}},
+			"profile": Relationship{Data: ResourceIdentifier{ID: "20", Type: "profile"}},
+		},
+	}
+	post := Resource{
+		ID:            "10",
+		Type:          "post",
+		Attributes:    NewAttributes(map[string]string{"title": "hello"}),
+		Relationships: Relationships{"likes": RelationshipHasMany{Data: []ResourceIdentifier{{ID: "30", Type: "like"}}}},
+	}
+	profile := Resource{ID: "20", Type: "profile", Attributes: NewAttributes(map[string]string{}), Relationships: Relationships{}}
+	like := Resource{ID: "30", Type: "like", Attributes: NewAttributes(map[string]string{}), Relationships: Relationships{}}
+
+	included := Resources{ShouldSort: true}
+	included.Append(post, profile, like)
+
+	q := Query{
+		Fields:  map[string][]string{"user": {"email", "username", "posts"}},
+		Include: []string{"posts.likes"},
+	}
+	data, res := q.Apply([]Resource{user}, included)
+
+	attrs, err := data[0].Attributes.JSONAPISerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := `{"username":"gooo","email":"gooo@example.com"}`; attrs != expect {
+		t.Errorf("expect %s, got %s", expect, attrs)
+	}
+
+	if _, ok := data[0].Relationships["profile"]; ok {
+		t.Errorf("expected profile relationship to be trimmed, got %v", data[0].Relationships)
+	}
+
+	keys := []string{}
+	for _, r := range res.Data {
+		keys = append(keys, r.Type+":"+r.ID)
+	}
+
+	if expect := []string{"like:30", "post:10"}; !reflect.DeepEqual(keys, expect) {
+		t.Errorf("expect %v, got %v", expect, keys)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	u, err := url.Parse("https://example.com/users?sort=username")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPagination(u, Page{Number: 2, Size: 10}, 20, 25)
+	links := p.Links()
+	expect := Links{
+		Self:  "https://example.com/users?page%5Bnumber%5D=2&page%5Bsize%5D=10&sort=username",
+		First: "https://example.com/users?page%5Bnumber%5D=1&page%5Bsize%5D=10&sort=username",
+		Prev:  "https://example.com/users?page%5Bnumber%5D=1&page%5Bsize%5D=10&sort=username",
+		Next:  "https://example.com/users?page%5Bnumber%5D=3&page%5Bsize%5D=10&sort=username",
+		Last:  "https://example.com/users?page%5Bnumber%5D=3&page%5Bsize%5D=10&sort=username",
+	}
+	if !reflect.DeepEqual(links, expect) {
+		t.Errorf("expect %+v, got %+v", expect, links)
+	}
+
+	meta, err := MergedMeta{p, NewAttributes(map[string]string{"key": "value"})}.JSONAPISerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := `{"page": {"number": 2, "size": 10, "total": 25, "total_pages": 3}, "key": "value"}`; meta != expect {
+		t.Errorf("expect %s, got %s", expect, meta)
+	}
+}
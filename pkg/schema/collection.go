@@ -1,10 +1,13 @@
 package schema
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/version-1/gooo/pkg/errors"
 	"github.com/version-1/gooo/pkg/generator"
 	"github.com/version-1/gooo/pkg/schema/internal/renderer"
 	"github.com/version-1/gooo/pkg/util"
@@ -34,6 +37,22 @@ func (s *SchemaCollection) collect() error {
 	}
 
 	path := filepath.Clean(fmt.Sprintf("%s/%s/schema.go", rootPath, s.Dir))
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	out, err := addSnapshotFields(src)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(src, out) {
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
 	list, err := p.Parse(path)
 	if err != nil {
 		return err
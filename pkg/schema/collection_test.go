@@ -1,7 +1,6 @@
 package schema
 
 import (
-	"path/filepath"
 	"testing"
 )
 
@@ -10,7 +9,7 @@ func TestSchemaCollection_Gen(t *testing.T) {
 
 	schemas := SchemaCollection{
 		URL:     "github.com/version-1/gooo",
-		Package: filepath.Base(dir),
+		Package: "fixtures",
 		Dir:     dir,
 	}
 
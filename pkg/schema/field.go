@@ -45,7 +45,7 @@ func (f Field) TableType() string {
 }
 
 func (f Field) IsMutable() bool {
-	return !f.Tag.Immutable && !f.Tag.Ignore
+	return !f.Tag.Immutable && !f.Tag.Ignore && !f.IsAssociation()
 }
 
 func (f Field) IsImmutable() bool {
@@ -69,7 +69,7 @@ func (f Field) IsRef() bool {
 }
 
 func (f Field) AssociationPrimaryKey() string {
-	if f.Association == nil {
+	if f.Association == nil || f.Association.Schema == nil {
 		return ""
 	}
 
@@ -82,8 +82,36 @@ type Validator struct {
 }
 
 type Association struct {
-	Slice  bool
+	Slice bool
+	// Schema is the associated schema. It is nil for polymorphic belongs-to
+	// associations, which may point to any schema.
 	Schema *Schema
+	// BelongsTo is true when the foreign key is a column of the owner.
+	BelongsTo bool
+	// ForeignKey is the column of the foreign key. It is on the owner for
+	// belongs-to associations and on the associated schema otherwise. It is
+	// empty when it can not be inferred, in which case the association is
+	// rendered but not preloaded.
+	ForeignKey string
+	// Polymorphic is the name of the polymorphic association. The records
+	// are keyed by <name>_id and <name>_type, which stores the resource type.
+	Polymorphic string
+	// PolymorphicTypes are the schemas a polymorphic belongs-to association
+	// may point to, i.e. the schemas declaring the has-many side.
+	PolymorphicTypes []PolymorphicType
+	// Through is the association of the owner the records are reached
+	// through.
+	Through string
+	// ThroughTarget is the association of the through schema holding the
+	// records.
+	ThroughTarget string
+}
+
+// PolymorphicType is a schema a polymorphic belongs-to association may point
+// to.
+type PolymorphicType struct {
+	Schema     string
+	PrimaryKey string
 }
 
 type validationKeys string
@@ -107,6 +135,9 @@ type FieldTag struct {
 	Association  bool
 	TableType    string
 	Validators   []string
+	Polymorphic  string
+	ForeignKey   string
+	Through      string
 }
 
 func parseTag(tag string) FieldTag {
@@ -149,6 +180,18 @@ func parseTag(tag string) FieldTag {
 			}
 		}
 
+		if strings.HasPrefix(t, "polymorphic=") {
+			options.Polymorphic = strings.TrimPrefix(t, "polymorphic=")
+		}
+
+		if strings.HasPrefix(t, "foreign_key=") {
+			options.ForeignKey = strings.TrimPrefix(t, "foreign_key=")
+		}
+
+		if strings.HasPrefix(t, "through=") {
+			options.Through = strings.TrimPrefix(t, "through=")
+		}
+
 		if strings.HasPrefix(t, "validation=") {
 			segments := strings.Split(t, "=")
 			if len(segments) > 1 {
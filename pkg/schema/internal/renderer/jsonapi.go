@@ -22,50 +22,56 @@ func (s SchemaTemplate) defineToJSONAPIResource() string {
 	str += "\n"
 
 	for _, ident := range s.Schema.AssociationFieldIdents() {
-		if ident.Slice {
+		name := ident.RelationshipName()
+		// each association is rendered in its own block so that the local
+		// variables do not collide.
+		str += "{\n"
+		switch {
+		case ident.BelongsTo():
+			str += s.belongsTo(ident)
+		case ident.Slice:
 			str += fmt.Sprintf(
 				`elements := []jsonapi.Resourcer{}
 				for _, ele := range obj.%s {
 					elements = append(elements, jsonapi.Resourcer(ele))
 				}
-				jsonapi.HasMany(r, includes, elements, "%s", func(ri *jsonapi.ResourceIdentifier, i int) {
+				jsonapi.HasManyNamed(r, includes, "%s", elements, "%s", func(ri *jsonapi.ResourceIdentifier, i int) {
 						id := obj.%s[i].%s
 						ri.ID = jsonapi.Stringify(id)
 				})`,
 				ident.FieldName,
+				name,
 				ident.TypeName,
 				ident.FieldName,
 				ident.PrimaryKey,
 			)
-			str += "\n"
-		} else {
-			if ident.Ref {
-				str += fmt.Sprintf(
-					`ele := obj.%s
-					if ele != nil {
-						jsonapi.HasOne(r, includes, ele, ele.%s, "%s")
-					}`,
-					ident.FieldName,
-					ident.PrimaryKey,
-					ident.TypeName,
-				)
-			} else {
-				str += fmt.Sprintf(
-					`ele := obj.%s
-					if ele.%s != (%s{}).%s {
-						jsonapi.HasOne(r, includes, ele, ele.%s, "%s")
-					}`,
-					ident.FieldName,
-					ident.PrimaryKey,
-					ident.TypeElementExpr,
-					ident.PrimaryKey,
-					ident.PrimaryKey,
-					ident.TypeName,
-				)
-			}
-			str += "\n"
+		case ident.Ref:
+			str += fmt.Sprintf(
+				`ele := obj.%s
+				if ele != nil {
+					jsonapi.HasOneNamed(r, includes, "%s", ele, ele.%s, "%s")
+				}`,
+				ident.FieldName,
+				name,
+				ident.PrimaryKey,
+				ident.TypeName,
+			)
+		default:
+			str += fmt.Sprintf(
+				`ele := obj.%s
+				if ele.%s != (%s{}).%s {
+					jsonapi.HasOneNamed(r, includes, "%s", ele, ele.%s, "%s")
+				}`,
+				ident.FieldName,
+				ident.PrimaryKey,
+				ident.TypeElementExpr,
+				ident.PrimaryKey,
+				name,
+				ident.PrimaryKey,
+				ident.TypeName,
+			)
 		}
-		str += "\n"
+		str += "\n}\n\n"
 	}
 
 	str += "\n"
@@ -80,6 +86,48 @@ func (s SchemaTemplate) defineToJSONAPIResource() string {
 	}.String()
 }
 
+// belongsTo renders the linkage from the foreign key of the owner, so that it
+// is present even when the associated resource is not loaded.
+func (s SchemaTemplate) belongsTo(ident AssociationIdent) string {
+	if ident.Polymorphic != "" {
+		return fmt.Sprintf(
+			`if obj.%s != (%s{}).%s {
+				jsonapi.BelongsTo(r, includes, "%s", obj.%s, obj.%s, obj.%s)
+			}`,
+			ident.ForeignKeyFieldName,
+			s.Schema.GetName(),
+			ident.ForeignKeyFieldName,
+			ident.RelationshipName(),
+			ident.FieldName,
+			ident.ForeignKeyFieldName,
+			ident.PolymorphicTypeFieldName,
+		)
+	}
+
+	loaded := fmt.Sprintf("obj.%s.%s != (%s{}).%s", ident.FieldName, ident.PrimaryKey, ident.TypeElementExpr, ident.PrimaryKey)
+	if ident.Ref {
+		loaded = fmt.Sprintf("obj.%s != nil", ident.FieldName)
+	}
+
+	return fmt.Sprintf(
+		`if obj.%s != (%s{}).%s {
+			var ele jsonapi.Resourcer
+			if %s {
+				ele = obj.%s
+			}
+			jsonapi.BelongsTo(r, includes, "%s", ele, obj.%s, "%s")
+		}`,
+		ident.ForeignKeyFieldName,
+		s.Schema.GetName(),
+		ident.ForeignKeyFieldName,
+		loaded,
+		ident.FieldName,
+		ident.RelationshipName(),
+		ident.ForeignKeyFieldName,
+		ident.TypeName,
+	)
+}
+
 func (s SchemaTemplate) defineJSONAPISerialize() string {
 	fields := []string{}
 	for _, n := range s.Schema.AttributeFieldNames() {
@@ -0,0 +1,271 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/version-1/gooo/pkg/schema/internal/template"
+	gooostrings "github.com/version-1/gooo/pkg/strings"
+)
+
+func (s SchemaTemplate) primaryKeyColumn() string {
+	return gooostrings.ToSnakeCase(s.Schema.PrimaryKey())
+}
+
+// hasUpdatedAt reports whether updated_at is maintained by the generated
+// updates.
+func (s SchemaTemplate) hasUpdatedAt() bool {
+	for _, c := range s.Schema.MutableColumns() {
+		if c == "updated_at" {
+			return false
+		}
+	}
+
+	for _, c := range s.Schema.Columns() {
+		if c == "updated_at" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanInserted scans the row returned by q into obj.
+const scanInserted = `if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+		return goooerrors.Wrap(err)
+	}`
+
+// useRunner makes the method run on the transaction of ctx when qr is nil.
+const useRunner = `qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+`
+
+// callHook calls the hook of the orm package when obj implements it.
+func callHook(iface, method string) string {
+	return fmt.Sprintf(`if h, ok := any(obj).(orm.%s); ok {
+			if err := h.%s(ctx, qr); err != nil {
+				return err
+			}
+		}
+
+	`, iface, method)
+}
+
+// atomically renders the write followed by the After hook. When obj
+// implements the hook, both run through orm.Atomic so that an error of the
+// hook rolls the write back.
+func atomically(iface, method, write string) string {
+	return fmt.Sprintf(`after, hook := any(obj).(orm.%s)
+		write := func(qr orm.QueryRunner) error {
+			%s
+
+			if hook {
+				return after.%s(ctx, qr)
+			}
+
+			return nil
+		}
+
+		if hook {
+			return orm.Atomic(ctx, qr, write)
+		}
+
+		return write(qr)`, iface, strings.TrimSpace(write), method)
+}
+
+func (s SchemaTemplate) takeSnapshot() string {
+	f := s.Schema.GetSnapshotField()
+	if f == "" {
+		return ""
+	}
+
+	values := []string{}
+	for _, n := range s.Schema.ColumnFieldNames() {
+		values = append(values, fmt.Sprintf("obj.%s", n))
+	}
+
+	return fmt.Sprintf("\nobj.%s.Take(obj.Columns(), []any{%s})\n", f, strings.Join(values, ", "))
+}
+
+func (s SchemaTemplate) definePersistence() string {
+	name := s.Schema.GetName()
+	receiver := template.Pointer(name)
+	table := s.Schema.GetTableName()
+	pk := s.Schema.PrimaryKey()
+	ctxArgs := []template.Arg{
+		{Name: "ctx", Type: "context.Context"},
+		{Name: "qr", Type: "queryer"},
+	}
+
+	validate := `if err := obj.validate(); err != nil {
+			return err
+		}
+
+	`
+	requirePrimaryKey := fmt.Sprintf(`zero, err := util.IsZero(obj.%s)
+		if err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if zero {
+			return goooerrors.Wrap(ErrPrimaryKeyMissing)
+		}
+
+	`, pk)
+	touch := ""
+	if s.hasUpdatedAt() {
+		touch = ".\nDoUpdateSet(\"updated_at\", query.Raw(\"NOW()\"))"
+	}
+
+	values := []string{}
+	for _, n := range s.Schema.MutableFieldNames() {
+		values = append(values, fmt.Sprintf("obj.%s", n))
+	}
+
+	str := ""
+	str += template.Method{
+		Receiver:    name,
+		Name:        "mutableValues",
+		Args:        []template.Arg{},
+		ReturnTypes: []string{"[]string", "[]any"},
+		Body: fmt.Sprintf(
+			"return []string{%s}, []any{%s}",
+			strings.Join(wrapQuote(s.Schema.MutableColumns()), ", "),
+			strings.Join(values, ", "),
+		),
+	}.String()
+
+	str += template.Method{
+		Receiver:    name,
+		Name:        "insertValues",
+		Args:        []template.Arg{},
+		ReturnTypes: []string{"[]string", "[]any"},
+		Body: fmt.Sprintf(`columns, values := obj.mutableValues()
+			// the primary key is inserted when it is set, otherwise it is left to the
+			// database.
+			if zero, _ := util.IsZero(obj.%s); !zero {
+				columns = append(columns, "%s")
+				values = append(values, obj.%s)
+			}
+
+			return columns, values`, pk, s.primaryKeyColumn(), pk),
+	}.String()
+
+	str += "// Create inserts obj and scans the inserted row back into it. BeforeCreate is\n"
+	str += "// called before obj is validated.\n"
+	str += template.Method{
+		Receiver:    receiver,
+		Name:        "Create",
+		Args:        ctxArgs,
+		ReturnTypes: []string{"error"},
+		Body: useRunner + callHook("BeforeCreator", "BeforeCreate") + validate + fmt.Sprintf(`columns, values := obj.insertValues()
+			q := query.InsertInto("%s").Columns(columns...).Values(values...).Returning(obj.Columns()...)
+			`, table) + atomically("AfterCreator", "AfterCreate", scanInserted),
+	}.String()
+
+	set := `for i, c := range columns {
+			q.Set(c, values[i])
+		}
+
+	`
+	if f := s.Schema.GetSnapshotField(); f != "" {
+		set = fmt.Sprintf(`changed := false
+			for i, c := range columns {
+				if obj.%s.Changed(c, values[i]) {
+					q.Set(c, values[i])
+					changed = true
+				}
+			}
+
+			if !changed {
+				return nil
+			}
+
+		`, f)
+	}
+	if s.hasUpdatedAt() {
+		set += "q.Set(\"updated_at\", query.Raw(\"NOW()\"))\n"
+	}
+
+	doc := "// Update writes the columns of obj by its primary key and scans the updated\n// row back into it.\n"
+	if s.Schema.GetSnapshotField() != "" {
+		doc = "// Update writes the columns of obj changed since it was scanned by its primary\n// key and scans the updated row back into it. Nothing is written when no\n// column is changed, and then AfterUpdate is not called.\n"
+	}
+	str += doc
+	str += template.Method{
+		Receiver:    receiver,
+		Name:        "Update",
+		Args:        ctxArgs,
+		ReturnTypes: []string{"error"},
+		Body: useRunner + callHook("BeforeUpdater", "BeforeUpdate") + validate + requirePrimaryKey + fmt.Sprintf(`columns, values := obj.mutableValues()
+			q := query.Update("%s")
+			%s
+
+			q.Where(query.Eq("%s", obj.%s)).Returning(obj.Columns()...)
+			`, table, strings.TrimSpace(set), s.primaryKeyColumn(), pk) + atomically("AfterUpdater", "AfterUpdate", `if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return goooerrors.Wrap(ErrNotFound)
+				}
+
+				return goooerrors.Wrap(err)
+			}`),
+	}.String()
+
+	str += "// Upsert inserts obj or, when the row conflicts on the target columns, updates\n"
+	str += "// the existing row. The target defaults to the primary key. The create hooks\n"
+	str += "// are called in either case.\n"
+	str += template.Method{
+		Receiver: receiver,
+		Name:     "Upsert",
+		Args: append(ctxArgs, template.Arg{
+			Name: "target",
+			Type: "...string",
+		}),
+		ReturnTypes: []string{"error"},
+		Body: useRunner + callHook("BeforeCreator", "BeforeCreate") + validate + fmt.Sprintf(`if len(target) == 0 {
+				target = []string{"%s"}
+			}
+
+			mutable, _ := obj.mutableValues()
+			columns, values := obj.insertValues()
+			q := query.InsertInto("%s").
+				Columns(columns...).
+				Values(values...).
+				OnConflict(target...).
+				DoUpdate(mutable...)%s.
+				Returning(obj.Columns()...)
+			`, s.primaryKeyColumn(), table, touch) + atomically("AfterCreator", "AfterCreate", scanInserted),
+	}.String()
+
+	str += "// Save creates obj when its primary key is zero and updates it otherwise.\n"
+	str += template.Method{
+		Receiver:    receiver,
+		Name:        "Save",
+		Args:        ctxArgs,
+		ReturnTypes: []string{"error"},
+		Body: fmt.Sprintf(`zero, err := util.IsZero(obj.%s)
+			if err != nil {
+				return goooerrors.Wrap(err)
+			}
+
+			if zero {
+				return obj.Create(ctx, qr)
+			}
+
+			return obj.Update(ctx, qr)`, pk),
+	}.String()
+
+	str += "// Reload reads obj again by its primary key, discarding unsaved changes.\n"
+	str += template.Method{
+		Receiver:    receiver,
+		Name:        "Reload",
+		Args:        ctxArgs,
+		ReturnTypes: []string{"error"},
+		Body:        "return obj.Find(ctx, qr)",
+	}.String()
+
+	return str
+}
@@ -0,0 +1,238 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/version-1/gooo/pkg/schema/internal/template"
+	gooostrings "github.com/version-1/gooo/pkg/strings"
+)
+
+// scopeName is the name of the scope of a model, e.g. Users for User.
+func scopeName(name string) string {
+	return gooostrings.ToPascalCase(gooostrings.ToPlural(name))
+}
+
+func preloadFuncName(name string) string {
+	return "Preload" + scopeName(name)
+}
+
+// definePreload defines the function loading the associations of a list of
+// the model with one query per association, and the Preload method.
+func (s SchemaTemplate) definePreload() string {
+	name := s.Schema.GetName()
+
+	cases := ""
+	for _, ident := range s.Schema.AssociationFieldIdents() {
+		cases += fmt.Sprintf("case \"%s\":\n", ident.FieldName)
+		switch {
+		case ident.Through != "":
+			cases += s.preloadThrough(ident)
+		case ident.ForeignKey == "":
+			cases += fmt.Sprintf(
+				"return goooerrors.Errorf(\"foreign key of association %s.%s not found. set foreign_key=\")\n",
+				name, ident.FieldName,
+			)
+		case ident.BelongsTo() && ident.Polymorphic != "":
+			cases += s.preloadPolymorphicBelongsTo(ident)
+		case ident.BelongsTo():
+			cases += s.preloadBelongsTo(ident)
+		default:
+			cases += s.preloadHas(ident)
+		}
+	}
+
+	str := fmt.Sprintf(`// %s loads the associations named by paths into list with one
+	// query per association. Nested associations are separated by dots.
+	func %s(ctx context.Context, qr queryer, list []%s, paths ...string) error {
+		if len(list) == 0 {
+			return nil
+		}
+
+		for _, p := range query.Preloads(paths...) {
+			switch p.Name {
+			%s
+			default:
+				return goooerrors.Errorf("association %%s not found on %s", p.Name)
+			}
+		}
+
+		return nil
+	}
+
+	`, preloadFuncName(name), preloadFuncName(name), name, strings.TrimSpace(cases), name)
+
+	str += template.Method{
+		Receiver: template.Pointer(name),
+		Name:     "Preload",
+		Args: []template.Arg{
+			{Name: "ctx", Type: "context.Context"},
+			{Name: "qr", Type: "queryer"},
+			{Name: "paths", Type: "...string"},
+		},
+		ReturnTypes: []string{"error"},
+		Body: fmt.Sprintf(`list := []%s{*obj}
+			if err := %s(ctx, qr, list, paths...); err != nil {
+				return err
+			}
+
+			*obj = list[0]
+			return nil`, name, preloadFuncName(name)),
+	}.String()
+
+	return str
+}
+
+// preloadRecords loads the records of typ matching where and preloads the
+// nested paths on them.
+func preloadRecords(typ, where string) string {
+	return fmt.Sprintf(`records, err := %s.Where(%s).All(ctx, qr)
+		if err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if err := %s(ctx, qr, records, p.Nested...); err != nil {
+			return err
+		}
+
+	`, scopeName(typ), where, preloadFuncName(typ))
+}
+
+func assignFirst(field string, ref bool) string {
+	if ref {
+		return fmt.Sprintf("list[i].%s = &g[0]", field)
+	}
+
+	return fmt.Sprintf("list[i].%s = g[0]", field)
+}
+
+func (s SchemaTemplate) preloadHas(ident AssociationIdent) string {
+	name := s.Schema.GetName()
+	primaryKey := s.Schema.PrimaryKey()
+
+	where := fmt.Sprintf(
+		"query.In(\"%s\", query.Keys(list, func(v %s) %s { return v.%s }))",
+		ident.ForeignKey, name, ident.KeyType, primaryKey,
+	)
+	if ident.Polymorphic != "" {
+		where = fmt.Sprintf("query.Eq(\"%s_type\", \"%s\"), %s", ident.Polymorphic, gooostrings.ToSnakeCase(name), where)
+	}
+
+	str := preloadRecords(ident.TypeElementExpr, where)
+	str += fmt.Sprintf(
+		"m := query.Group(records, func(v %s) %s { return v.%s })\n",
+		ident.TypeElementExpr, ident.KeyType, ident.AssociatedForeignKeyFieldName,
+	)
+
+	if ident.Slice {
+		return str + fmt.Sprintf(`for i := range list {
+			list[i].%s = append([]%s{}, m[list[i].%s]...)
+		}
+		`, ident.FieldName, ident.TypeElementExpr, primaryKey)
+	}
+
+	return str + fmt.Sprintf(`for i := range list {
+		if g := m[list[i].%s]; len(g) > 0 {
+			%s
+		}
+	}
+	`, primaryKey, assignFirst(ident.FieldName, ident.Ref))
+}
+
+func (s SchemaTemplate) preloadBelongsTo(ident AssociationIdent) string {
+	name := s.Schema.GetName()
+
+	str := preloadRecords(ident.TypeElementExpr, fmt.Sprintf(
+		"query.In(\"%s\", query.Keys(list, func(v %s) %s { return v.%s }))",
+		gooostrings.ToSnakeCase(ident.PrimaryKey), name, ident.KeyType, ident.ForeignKeyFieldName,
+	))
+	str += fmt.Sprintf(
+		"m := query.Group(records, func(v %s) %s { return v.%s })\n",
+		ident.TypeElementExpr, ident.KeyType, ident.PrimaryKey,
+	)
+
+	return str + fmt.Sprintf(`for i := range list {
+		if g := m[list[i].%s]; len(g) > 0 {
+			%s
+		}
+	}
+	`, ident.ForeignKeyFieldName, assignFirst(ident.FieldName, ident.Ref))
+}
+
+// preloadPolymorphicBelongsTo loads the records of each type the association
+// may point to.
+func (s SchemaTemplate) preloadPolymorphicBelongsTo(ident AssociationIdent) string {
+	name := s.Schema.GetName()
+
+	str := ""
+	for _, t := range ident.PolymorphicTypes {
+		str += "{\n"
+		str += fmt.Sprintf(`keys := []%s{}
+			for _, v := range list {
+				if v.%s == "%s" {
+					keys = append(keys, v.%s)
+				}
+			}
+
+			`, ident.KeyType, ident.PolymorphicTypeFieldName, t.TypeName, ident.ForeignKeyFieldName)
+		str += preloadRecords(t.TypeElementExpr, fmt.Sprintf(
+			"query.In(\"%s\", keys)", gooostrings.ToSnakeCase(t.PrimaryKey),
+		))
+		str += fmt.Sprintf(
+			"m := query.Group(records, func(v %s) %s { return v.%s })\n",
+			t.TypeElementExpr, ident.KeyType, t.PrimaryKey,
+		)
+		str += fmt.Sprintf(`for i := range list {
+				if g := m[list[i].%s]; list[i].%s == "%s" && len(g) > 0 {
+					list[i].%s = g[0]
+				}
+			}
+			`, ident.ForeignKeyFieldName, ident.PolymorphicTypeFieldName, t.TypeName, ident.FieldName)
+		str += "}\n"
+	}
+
+	if str == "" {
+		return fmt.Sprintf("return goooerrors.Errorf(\"no types of association %%s on %s\", p.Name)\n", name)
+	}
+
+	return str
+}
+
+// preloadThrough preloads the through association and its target, and then
+// collects the targets.
+func (s SchemaTemplate) preloadThrough(ident AssociationIdent) string {
+	name := s.Schema.GetName()
+	path := ident.ThroughFieldName + "." + ident.ThroughTarget
+
+	collect := fmt.Sprintf("list[i].%s = append(list[i].%s, t.%s)", ident.FieldName, ident.FieldName, ident.ThroughTarget)
+	if ident.ThroughTargetSlice {
+		collect = fmt.Sprintf("list[i].%s = append(list[i].%s, t.%s...)", ident.FieldName, ident.FieldName, ident.ThroughTarget)
+	} else if ident.ThroughTargetRef {
+		collect = fmt.Sprintf("if t.%s != nil {\n%s\n}", ident.ThroughTarget, fmt.Sprintf(
+			"list[i].%s = append(list[i].%s, *t.%s)", ident.FieldName, ident.FieldName, ident.ThroughTarget,
+		))
+	}
+
+	each := fmt.Sprintf("{\nt := through[i].%s\n%s\n}", ident.ThroughFieldName, collect)
+	if ident.ThroughSlice {
+		each = fmt.Sprintf("for _, t := range through[i].%s {\n%s\n}", ident.ThroughFieldName, collect)
+	} else if ident.ThroughRef {
+		each = fmt.Sprintf("if t := through[i].%s; t != nil {\n%s\n}", ident.ThroughFieldName, collect)
+	}
+
+	return fmt.Sprintf(`through := append([]%s{}, list...)
+		nested := []string{"%s"}
+		for _, n := range p.Nested {
+			nested = append(nested, "%s." + n)
+		}
+
+		if err := %s(ctx, qr, through, nested...); err != nil {
+			return err
+		}
+
+		for i := range through {
+			list[i].%s = []%s{}
+			%s
+		}
+	`, name, path, path, preloadFuncName(name), ident.FieldName, ident.TypeElementExpr, each)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/version-1/gooo/pkg/schema/internal/template"
+	gooostrings "github.com/version-1/gooo/pkg/strings"
 	"github.com/version-1/gooo/pkg/util"
 )
 
@@ -16,6 +17,8 @@ var schemaPackage = "\"github.com/version-1/gooo/pkg/schema\""
 var utilPackage = "\"github.com/version-1/gooo/pkg/util\""
 var stringsPackage = "gooostrings \"github.com/version-1/gooo/pkg/strings\""
 var jsonapiPackage = "\"github.com/version-1/gooo/pkg/presenter/jsonapi\""
+var ormPackage = "\"github.com/version-1/gooo/pkg/datasource/orm\""
+var queryPackage = "\"github.com/version-1/gooo/pkg/datasource/query\""
 
 type AssociationIdent struct {
 	FieldName       string
@@ -24,6 +27,48 @@ type AssociationIdent struct {
 	TypeName        string
 	Slice           bool
 	Ref             bool
+	// ForeignKeyFieldName is the field of the owner holding the foreign key
+	// of a belongs-to association.
+	ForeignKeyFieldName string
+	// PolymorphicTypeFieldName is the field of the owner holding the type of
+	// a polymorphic belongs-to association.
+	PolymorphicTypeFieldName string
+	Polymorphic              string
+	// PolymorphicTypes are the types a polymorphic belongs-to association
+	// may point to.
+	PolymorphicTypes []PolymorphicIdent
+	// ForeignKey is the column of the foreign key.
+	ForeignKey string
+	// AssociatedForeignKeyFieldName is the field of the associated type
+	// holding the foreign key of a has-one or has-many association.
+	AssociatedForeignKeyFieldName string
+	// KeyType is the type of the key the records are joined on.
+	KeyType string
+	Through string
+	// ThroughFieldName is the association of the owner named by Through and
+	// ThroughTarget is the association of its type holding the records.
+	ThroughFieldName   string
+	ThroughSlice       bool
+	ThroughRef         bool
+	ThroughTarget      string
+	ThroughTargetSlice bool
+	ThroughTargetRef   bool
+}
+
+type PolymorphicIdent struct {
+	TypeElementExpr string
+	TypeName        string
+	PrimaryKey      string
+}
+
+// RelationshipName is the key of the association in the relationships of the
+// JSON:API resource, which include paths refer to.
+func (a AssociationIdent) RelationshipName() string {
+	return gooostrings.ToSnakeCase(a.FieldName)
+}
+
+func (a AssociationIdent) BelongsTo() bool {
+	return a.ForeignKeyFieldName != ""
 }
 
 type schema interface {
@@ -37,7 +82,8 @@ type schema interface {
 	PrimaryKey() string
 	Columns() []string
 	ColumnFieldNames() []string
-	SetClause() []string
+	ColumnFieldTypes() []string
+	GetSnapshotField() string
 }
 
 type SchemaTemplate struct {
@@ -73,6 +119,8 @@ func (s SchemaTemplate) Render() (string, error) {
 		),
 	}.String()
 
+	str += s.defineScope()
+
 	// scan
 	scanFields := []string{}
 	for _, n := range s.Schema.ColumnFieldNames() {
@@ -91,9 +139,10 @@ func (s SchemaTemplate) Render() (string, error) {
 			Body: fmt.Sprintf(`if err := rows.Scan(%s); err != nil {
 					return err
 				}
-
+				%s
 				return nil`,
 				strings.Join(scanFields, ", "),
+				s.takeSnapshot(),
 			),
 		},
 		{
@@ -104,7 +153,7 @@ func (s SchemaTemplate) Render() (string, error) {
 				{Name: "qr", Type: "queryer"},
 			},
 			ReturnTypes: []string{"error"},
-			Body: fmt.Sprintf(`zero, err := util.IsZero(obj.ID)
+			Body: useRunner + fmt.Sprintf(`zero, err := util.IsZero(obj.ID)
 			if err != nil {
 				return goooerrors.Wrap(err)
 			}
@@ -113,12 +162,10 @@ func (s SchemaTemplate) Render() (string, error) {
 			  return goooerrors.Wrap(ErrPrimaryKeyMissing)
 			}
 
-			query := "DELETE FROM %s WHERE id = $1"
-			if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
-				return goooerrors.Wrap(err)
-			}
-
-			return nil`, s.Schema.GetTableName()),
+			`+callHook("BeforeDestroyer", "BeforeDestroy")+`query := "DELETE FROM %s WHERE id = $1"
+			`+atomically("AfterDestroyer", "AfterDestroy", `if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
+				return goooerrors.Wrap(ormerrors.Translate(err))
+			}`), s.Schema.GetTableName()),
 		},
 		{
 			Receiver: receiver,
@@ -128,7 +175,7 @@ func (s SchemaTemplate) Render() (string, error) {
 				{Name: "qr", Type: "queryer"},
 			},
 			ReturnTypes: []string{"error"},
-			Body: fmt.Sprintf(`zero, err := util.IsZero(obj.ID)
+			Body: useRunner + fmt.Sprintf(`zero, err := util.IsZero(obj.ID)
 			if err != nil {
 				return goooerrors.Wrap(err)
 			}
@@ -148,7 +195,7 @@ func (s SchemaTemplate) Render() (string, error) {
 				return goooerrors.Wrap(err)
 			}
 
-			return nil`,
+			`+callHook("AfterFinder", "AfterFind")+`return nil`,
 				strings.Join(s.Schema.Columns(), ", "),
 				s.Schema.GetTableName(),
 			),
@@ -159,7 +206,8 @@ func (s SchemaTemplate) Render() (string, error) {
 		str += m.String()
 	}
 
-	str += s.defineSave()
+	str += s.definePersistence()
+	str += s.definePreload()
 	str += s.defineAssign()
 	str += s.defineValidate()
 	str += s.defineJSONAPISerialize()
@@ -168,6 +216,35 @@ func (s SchemaTemplate) Render() (string, error) {
 	return pretify(s.Filename(), str)
 }
 
+// defineScope defines a typed column per column and the scope of the model,
+// e.g. Users.Where(UserEmail.Eq(email)).All(ctx, qr).
+func (s SchemaTemplate) defineScope() string {
+	name := s.Schema.GetName()
+	columns := s.Schema.Columns()
+	types := s.Schema.ColumnFieldTypes()
+
+	str := "const (\n"
+	for i, n := range s.Schema.ColumnFieldNames() {
+		str += fmt.Sprintf("%s%s query.Column[%s] = \"%s\"\n", name, n, types[i], columns[i])
+	}
+	str += ")\n\n"
+
+	str += fmt.Sprintf(`var %s = query.NewScope(query.Table[%s]{
+		Name:    "%s",
+		Columns: %s{}.Columns(),
+		Scan: func(rows query.Scanner) (%s, error) {
+			obj := %s{}
+			err := obj.Scan(rows)
+			return obj, err
+		},
+		NotFound: ErrNotFound,
+	})
+
+`, scopeName(name), name, s.Schema.GetTableName(), name, name, name)
+
+	return str
+}
+
 func (s SchemaTemplate) defineValidate() string {
 	str := ""
 	str += "return nil"
@@ -181,51 +258,6 @@ func (s SchemaTemplate) defineValidate() string {
 	}.String()
 }
 
-func (s SchemaTemplate) defineSave() string {
-	query := fmt.Sprintf(`
-		INSERT INTO %s (%s) VALUES ($1, $2, $3)
-		ON CONFLICT(id) DO UPDATE SET %s
-		RETURNING %s
-  `,
-		s.Schema.GetTableName(),
-		strings.Join(s.Schema.MutableColumns(), ", "),
-		strings.Join(s.Schema.SetClause(), ", "),
-		strings.Join(s.Schema.Columns(), ", "),
-	)
-
-	mutableValues := []string{}
-	for _, n := range s.Schema.MutableFieldNames() {
-		mutableValues = append(mutableValues, fmt.Sprintf("obj.%s", n))
-	}
-
-	validateStr := `if err := obj.validate(); err != nil {
-				return err
-			}
-		`
-
-	return template.Method{
-		Receiver: template.Pointer(s.Schema.GetName()),
-		Name:     "Save",
-		Args: []template.Arg{
-			{Name: "ctx", Type: "context.Context"},
-			{Name: "qr", Type: "queryer"},
-		},
-		ReturnTypes: []string{"error"},
-		Body: fmt.Sprintf(
-			validateStr+
-				"query := `%s`\n"+`
-			row := qr.QueryRowContext(ctx, query, %s)
-			if err := obj.Scan(row); err != nil {
-				return err
-			}
-
-			return nil`,
-			query,
-			strings.Join(mutableValues, ", "),
-		),
-	}.String()
-}
-
 func (s SchemaTemplate) defineAssign() string {
 	fields := []string{}
 	for _, n := range s.Schema.FieldNames() {
@@ -250,6 +282,8 @@ func (s SchemaTemplate) libs() []string {
 		ormerrPackage,
 		stringsPackage,
 		jsonapiPackage,
+		ormPackage,
+		queryPackage,
 		utilPackage,
 		"\"github.com/google/uuid\"",
 		"\"strings\"",
@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/version-1/gooo/pkg/datasource/orm"
 	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
+	"github.com/version-1/gooo/pkg/datasource/query"
 	goooerrors "github.com/version-1/gooo/pkg/errors"
 	"github.com/version-1/gooo/pkg/presenter/jsonapi"
 	"github.com/version-1/gooo/pkg/util"
@@ -17,15 +20,41 @@ func (obj Like) Columns() []string {
 	return []string{"id", "likeable_id", "likeable_type", "created_at", "updated_at"}
 }
 
+const (
+	LikeID           query.Column[int]       = "id"
+	LikeLikeableID   query.Column[int]       = "likeable_id"
+	LikeLikeableType query.Column[string]    = "likeable_type"
+	LikeCreatedAt    query.Column[time.Time] = "created_at"
+	LikeUpdatedAt    query.Column[time.Time] = "updated_at"
+)
+
+var Likes = query.NewScope(query.Table[Like]{
+	Name:    "likes",
+	Columns: Like{}.Columns(),
+	Scan: func(rows query.Scanner) (Like, error) {
+		obj := Like{}
+		err := obj.Scan(rows)
+		return obj, err
+	},
+	NotFound: ErrNotFound,
+})
+
 func (obj *Like) Scan(rows scanner) error {
 	if err := rows.Scan(&obj.ID, &obj.LikeableID, &obj.LikeableType, &obj.CreatedAt, &obj.UpdatedAt); err != nil {
 		return err
 	}
 
+	obj.snapshot.Take(obj.Columns(), []any{obj.ID, obj.LikeableID, obj.LikeableType, obj.CreatedAt, obj.UpdatedAt})
+
 	return nil
 }
 
 func (obj *Like) Destroy(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -35,15 +64,39 @@ func (obj *Like) Destroy(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(ErrPrimaryKeyMissing)
 	}
 
+	if h, ok := any(obj).(orm.BeforeDestroyer); ok {
+		if err := h.BeforeDestroy(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	query := "DELETE FROM likes WHERE id = $1"
-	if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
-		return goooerrors.Wrap(err)
+	after, hook := any(obj).(orm.AfterDestroyer)
+	write := func(qr orm.QueryRunner) error {
+		if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
+			return goooerrors.Wrap(ormerrors.Translate(err))
+		}
+
+		if hook {
+			return after.AfterDestroy(ctx, qr)
+		}
+
+		return nil
 	}
 
-	return nil
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
 }
 
 func (obj *Like) Find(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -64,24 +117,260 @@ func (obj *Like) Find(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(err)
 	}
 
+	if h, ok := any(obj).(orm.AfterFinder); ok {
+		if err := h.AfterFind(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (obj *Like) Save(ctx context.Context, qr queryer) error {
+func (obj Like) mutableValues() ([]string, []any) {
+	return []string{"likeable_id", "likeable_type"}, []any{obj.LikeableID, obj.LikeableType}
+}
+
+func (obj Like) insertValues() ([]string, []any) {
+	columns, values := obj.mutableValues()
+	// the primary key is inserted when it is set, otherwise it is left to the
+	// database.
+	if zero, _ := util.IsZero(obj.ID); !zero {
+		columns = append(columns, "id")
+		values = append(values, obj.ID)
+	}
+
+	return columns, values
+}
+
+// Create inserts obj and scans the inserted row back into it. BeforeCreate is
+// called before obj is validated.
+func (obj *Like) Create(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	if err := obj.validate(); err != nil {
 		return err
 	}
-	query := `
-		INSERT INTO likes (likeable_id, likeable_type) VALUES ($1, $2, $3)
-		ON CONFLICT(id) DO UPDATE SET likeable_id = $1, likeable_type = $2, updated_at = NOW()
-		RETURNING id, likeable_id, likeable_type, created_at, updated_at
-  `
 
-	row := qr.QueryRowContext(ctx, query, obj.LikeableID, obj.LikeableType)
-	if err := obj.Scan(row); err != nil {
+	columns, values := obj.insertValues()
+	q := query.InsertInto("likes").Columns(columns...).Values(values...).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Update writes the columns of obj changed since it was scanned by its primary
+// key and scans the updated row back into it. Nothing is written when no
+// column is changed, and then AfterUpdate is not called.
+func (obj *Like) Update(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeUpdater); ok {
+		if err := h.BeforeUpdate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
+		return err
+	}
+
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return goooerrors.Wrap(ErrPrimaryKeyMissing)
+	}
+
+	columns, values := obj.mutableValues()
+	q := query.Update("likes")
+	changed := false
+	for i, c := range columns {
+		if obj.snapshot.Changed(c, values[i]) {
+			q.Set(c, values[i])
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	q.Set("updated_at", query.Raw("NOW()"))
+
+	q.Where(query.Eq("id", obj.ID)).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterUpdater)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return goooerrors.Wrap(ErrNotFound)
+			}
+
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterUpdate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Upsert inserts obj or, when the row conflicts on the target columns, updates
+// the existing row. The target defaults to the primary key. The create hooks
+// are called in either case.
+func (obj *Like) Upsert(ctx context.Context, qr queryer, target ...string) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
 		return err
 	}
 
+	if len(target) == 0 {
+		target = []string{"id"}
+	}
+
+	mutable, _ := obj.mutableValues()
+	columns, values := obj.insertValues()
+	q := query.InsertInto("likes").
+		Columns(columns...).
+		Values(values...).
+		OnConflict(target...).
+		DoUpdate(mutable...).
+		DoUpdateSet("updated_at", query.Raw("NOW()")).
+		Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Save creates obj when its primary key is zero and updates it otherwise.
+func (obj *Like) Save(ctx context.Context, qr queryer) error {
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return obj.Create(ctx, qr)
+	}
+
+	return obj.Update(ctx, qr)
+}
+
+// Reload reads obj again by its primary key, discarding unsaved changes.
+func (obj *Like) Reload(ctx context.Context, qr queryer) error {
+	return obj.Find(ctx, qr)
+}
+
+// PreloadLikes loads the associations named by paths into list with one
+// query per association. Nested associations are separated by dots.
+func PreloadLikes(ctx context.Context, qr queryer, list []Like, paths ...string) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	for _, p := range query.Preloads(paths...) {
+		switch p.Name {
+		case "Likeable":
+			{
+				keys := []int{}
+				for _, v := range list {
+					if v.LikeableType == "post" {
+						keys = append(keys, v.LikeableID)
+					}
+				}
+
+				records, err := Posts.Where(query.In("id", keys)).All(ctx, qr)
+				if err != nil {
+					return goooerrors.Wrap(err)
+				}
+
+				if err := PreloadPosts(ctx, qr, records, p.Nested...); err != nil {
+					return err
+				}
+
+				m := query.Group(records, func(v Post) int { return v.ID })
+				for i := range list {
+					if g := m[list[i].LikeableID]; list[i].LikeableType == "post" && len(g) > 0 {
+						list[i].Likeable = g[0]
+					}
+				}
+			}
+		default:
+			return goooerrors.Errorf("association %s not found on Like", p.Name)
+		}
+	}
+
+	return nil
+}
+
+func (obj *Like) Preload(ctx context.Context, qr queryer, paths ...string) error {
+	list := []Like{*obj}
+	if err := PreloadLikes(ctx, qr, list, paths...); err != nil {
+		return err
+	}
+
+	*obj = list[0]
 	return nil
 }
 
@@ -91,6 +380,7 @@ func (obj *Like) Assign(v Like) {
 	obj.LikeableType = v.LikeableType
 	obj.CreatedAt = v.CreatedAt
 	obj.UpdatedAt = v.UpdatedAt
+	obj.Likeable = v.Likeable
 }
 
 func (obj Like) validate() ormerrors.ValidationError {
@@ -116,5 +406,11 @@ func (obj Like) ToJSONAPIResource() (jsonapi.Resource, jsonapi.Resources) {
 		Relationships: jsonapi.Relationships{},
 	}
 
+	{
+		if obj.LikeableID != (Like{}).LikeableID {
+			jsonapi.BelongsTo(r, includes, "likeable", obj.Likeable, obj.LikeableID, obj.LikeableType)
+		}
+	}
+
 	return *r, *includes
 }
@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/version-1/gooo/pkg/datasource/orm"
 	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
+	"github.com/version-1/gooo/pkg/datasource/query"
 	goooerrors "github.com/version-1/gooo/pkg/errors"
 	"github.com/version-1/gooo/pkg/presenter/jsonapi"
 	"github.com/version-1/gooo/pkg/util"
@@ -17,15 +20,42 @@ func (obj Post) Columns() []string {
 	return []string{"id", "user_id", "title", "body", "created_at", "updated_at"}
 }
 
+const (
+	PostID        query.Column[int]       = "id"
+	PostUserID    query.Column[int]       = "user_id"
+	PostTitle     query.Column[string]    = "title"
+	PostBody      query.Column[string]    = "body"
+	PostCreatedAt query.Column[time.Time] = "created_at"
+	PostUpdatedAt query.Column[time.Time] = "updated_at"
+)
+
+var Posts = query.NewScope(query.Table[Post]{
+	Name:    "posts",
+	Columns: Post{}.Columns(),
+	Scan: func(rows query.Scanner) (Post, error) {
+		obj := Post{}
+		err := obj.Scan(rows)
+		return obj, err
+	},
+	NotFound: ErrNotFound,
+})
+
 func (obj *Post) Scan(rows scanner) error {
 	if err := rows.Scan(&obj.ID, &obj.UserID, &obj.Title, &obj.Body, &obj.CreatedAt, &obj.UpdatedAt); err != nil {
 		return err
 	}
 
+	obj.snapshot.Take(obj.Columns(), []any{obj.ID, obj.UserID, obj.Title, obj.Body, obj.CreatedAt, obj.UpdatedAt})
+
 	return nil
 }
 
 func (obj *Post) Destroy(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -35,15 +65,39 @@ func (obj *Post) Destroy(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(ErrPrimaryKeyMissing)
 	}
 
+	if h, ok := any(obj).(orm.BeforeDestroyer); ok {
+		if err := h.BeforeDestroy(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	query := "DELETE FROM posts WHERE id = $1"
-	if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
-		return goooerrors.Wrap(err)
+	after, hook := any(obj).(orm.AfterDestroyer)
+	write := func(qr orm.QueryRunner) error {
+		if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
+			return goooerrors.Wrap(ormerrors.Translate(err))
+		}
+
+		if hook {
+			return after.AfterDestroy(ctx, qr)
+		}
+
+		return nil
 	}
 
-	return nil
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
 }
 
 func (obj *Post) Find(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -64,24 +118,265 @@ func (obj *Post) Find(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(err)
 	}
 
+	if h, ok := any(obj).(orm.AfterFinder); ok {
+		if err := h.AfterFind(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (obj *Post) Save(ctx context.Context, qr queryer) error {
+func (obj Post) mutableValues() ([]string, []any) {
+	return []string{"user_id", "title", "body"}, []any{obj.UserID, obj.Title, obj.Body}
+}
+
+func (obj Post) insertValues() ([]string, []any) {
+	columns, values := obj.mutableValues()
+	// the primary key is inserted when it is set, otherwise it is left to the
+	// database.
+	if zero, _ := util.IsZero(obj.ID); !zero {
+		columns = append(columns, "id")
+		values = append(values, obj.ID)
+	}
+
+	return columns, values
+}
+
+// Create inserts obj and scans the inserted row back into it. BeforeCreate is
+// called before obj is validated.
+func (obj *Post) Create(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	if err := obj.validate(); err != nil {
 		return err
 	}
-	query := `
-		INSERT INTO posts (user_id, title, body, user, likes) VALUES ($1, $2, $3)
-		ON CONFLICT(id) DO UPDATE SET user_id = $1, title = $2, body = $3, user = $4, likes = $5, updated_at = NOW()
-		RETURNING id, user_id, title, body, created_at, updated_at
-  `
 
-	row := qr.QueryRowContext(ctx, query, obj.UserID, obj.Title, obj.Body, obj.User, obj.Likes)
-	if err := obj.Scan(row); err != nil {
+	columns, values := obj.insertValues()
+	q := query.InsertInto("posts").Columns(columns...).Values(values...).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Update writes the columns of obj changed since it was scanned by its primary
+// key and scans the updated row back into it. Nothing is written when no
+// column is changed, and then AfterUpdate is not called.
+func (obj *Post) Update(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeUpdater); ok {
+		if err := h.BeforeUpdate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
+		return err
+	}
+
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return goooerrors.Wrap(ErrPrimaryKeyMissing)
+	}
+
+	columns, values := obj.mutableValues()
+	q := query.Update("posts")
+	changed := false
+	for i, c := range columns {
+		if obj.snapshot.Changed(c, values[i]) {
+			q.Set(c, values[i])
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	q.Set("updated_at", query.Raw("NOW()"))
+
+	q.Where(query.Eq("id", obj.ID)).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterUpdater)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return goooerrors.Wrap(ErrNotFound)
+			}
+
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterUpdate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Upsert inserts obj or, when the row conflicts on the target columns, updates
+// the existing row. The target defaults to the primary key. The create hooks
+// are called in either case.
+func (obj *Post) Upsert(ctx context.Context, qr queryer, target ...string) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
 		return err
 	}
 
+	if len(target) == 0 {
+		target = []string{"id"}
+	}
+
+	mutable, _ := obj.mutableValues()
+	columns, values := obj.insertValues()
+	q := query.InsertInto("posts").
+		Columns(columns...).
+		Values(values...).
+		OnConflict(target...).
+		DoUpdate(mutable...).
+		DoUpdateSet("updated_at", query.Raw("NOW()")).
+		Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Save creates obj when its primary key is zero and updates it otherwise.
+func (obj *Post) Save(ctx context.Context, qr queryer) error {
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return obj.Create(ctx, qr)
+	}
+
+	return obj.Update(ctx, qr)
+}
+
+// Reload reads obj again by its primary key, discarding unsaved changes.
+func (obj *Post) Reload(ctx context.Context, qr queryer) error {
+	return obj.Find(ctx, qr)
+}
+
+// PreloadPosts loads the associations named by paths into list with one
+// query per association. Nested associations are separated by dots.
+func PreloadPosts(ctx context.Context, qr queryer, list []Post, paths ...string) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	for _, p := range query.Preloads(paths...) {
+		switch p.Name {
+		case "User":
+			records, err := Users.Where(query.In("id", query.Keys(list, func(v Post) int { return v.UserID }))).All(ctx, qr)
+			if err != nil {
+				return goooerrors.Wrap(err)
+			}
+
+			if err := PreloadUsers(ctx, qr, records, p.Nested...); err != nil {
+				return err
+			}
+
+			m := query.Group(records, func(v User) int { return v.ID })
+			for i := range list {
+				if g := m[list[i].UserID]; len(g) > 0 {
+					list[i].User = g[0]
+				}
+			}
+		case "Likes":
+			records, err := Likes.Where(query.Eq("likeable_type", "post"), query.In("likeable_id", query.Keys(list, func(v Post) int { return v.ID }))).All(ctx, qr)
+			if err != nil {
+				return goooerrors.Wrap(err)
+			}
+
+			if err := PreloadLikes(ctx, qr, records, p.Nested...); err != nil {
+				return err
+			}
+
+			m := query.Group(records, func(v Like) int { return v.LikeableID })
+			for i := range list {
+				list[i].Likes = append([]Like{}, m[list[i].ID]...)
+			}
+		default:
+			return goooerrors.Errorf("association %s not found on Post", p.Name)
+		}
+	}
+
+	return nil
+}
+
+func (obj *Post) Preload(ctx context.Context, qr queryer, paths ...string) error {
+	list := []Post{*obj}
+	if err := PreloadPosts(ctx, qr, list, paths...); err != nil {
+		return err
+	}
+
+	*obj = list[0]
 	return nil
 }
 
@@ -120,19 +415,26 @@ func (obj Post) ToJSONAPIResource() (jsonapi.Resource, jsonapi.Resources) {
 		Relationships: jsonapi.Relationships{},
 	}
 
-	ele := obj.User
-	if ele.ID != (User{}).ID {
-		jsonapi.HasOne(r, includes, ele, ele.ID, "user")
+	{
+		if obj.UserID != (Post{}).UserID {
+			var ele jsonapi.Resourcer
+			if obj.User.ID != (User{}).ID {
+				ele = obj.User
+			}
+			jsonapi.BelongsTo(r, includes, "user", ele, obj.UserID, "user")
+		}
 	}
 
-	elements := []jsonapi.Resourcer{}
-	for _, ele := range obj.Likes {
-		elements = append(elements, jsonapi.Resourcer(ele))
+	{
+		elements := []jsonapi.Resourcer{}
+		for _, ele := range obj.Likes {
+			elements = append(elements, jsonapi.Resourcer(ele))
+		}
+		jsonapi.HasManyNamed(r, includes, "likes", elements, "like", func(ri *jsonapi.ResourceIdentifier, i int) {
+			id := obj.Likes[i].ID
+			ri.ID = jsonapi.Stringify(id)
+		})
 	}
-	jsonapi.HasMany(r, includes, elements, "like", func(ri *jsonapi.ResourceIdentifier, i int) {
-		id := obj.Likes[i].ID
-		ri.ID = jsonapi.Stringify(id)
-	})
 
 	return *r, *includes
 }
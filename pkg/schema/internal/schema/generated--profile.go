@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/version-1/gooo/pkg/datasource/orm"
 	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
+	"github.com/version-1/gooo/pkg/datasource/query"
 	goooerrors "github.com/version-1/gooo/pkg/errors"
 	"github.com/version-1/gooo/pkg/presenter/jsonapi"
 	"github.com/version-1/gooo/pkg/util"
@@ -17,15 +20,41 @@ func (obj Profile) Columns() []string {
 	return []string{"id", "user_id", "bio", "created_at", "updated_at"}
 }
 
+const (
+	ProfileID        query.Column[int]       = "id"
+	ProfileUserID    query.Column[int]       = "user_id"
+	ProfileBio       query.Column[string]    = "bio"
+	ProfileCreatedAt query.Column[time.Time] = "created_at"
+	ProfileUpdatedAt query.Column[time.Time] = "updated_at"
+)
+
+var Profiles = query.NewScope(query.Table[Profile]{
+	Name:    "profiles",
+	Columns: Profile{}.Columns(),
+	Scan: func(rows query.Scanner) (Profile, error) {
+		obj := Profile{}
+		err := obj.Scan(rows)
+		return obj, err
+	},
+	NotFound: ErrNotFound,
+})
+
 func (obj *Profile) Scan(rows scanner) error {
 	if err := rows.Scan(&obj.ID, &obj.UserID, &obj.Bio, &obj.CreatedAt, &obj.UpdatedAt); err != nil {
 		return err
 	}
 
+	obj.snapshot.Take(obj.Columns(), []any{obj.ID, obj.UserID, obj.Bio, obj.CreatedAt, obj.UpdatedAt})
+
 	return nil
 }
 
 func (obj *Profile) Destroy(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -35,15 +64,39 @@ func (obj *Profile) Destroy(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(ErrPrimaryKeyMissing)
 	}
 
+	if h, ok := any(obj).(orm.BeforeDestroyer); ok {
+		if err := h.BeforeDestroy(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	query := "DELETE FROM profiles WHERE id = $1"
-	if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
-		return goooerrors.Wrap(err)
+	after, hook := any(obj).(orm.AfterDestroyer)
+	write := func(qr orm.QueryRunner) error {
+		if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
+			return goooerrors.Wrap(ormerrors.Translate(err))
+		}
+
+		if hook {
+			return after.AfterDestroy(ctx, qr)
+		}
+
+		return nil
 	}
 
-	return nil
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
 }
 
 func (obj *Profile) Find(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -64,24 +117,236 @@ func (obj *Profile) Find(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(err)
 	}
 
+	if h, ok := any(obj).(orm.AfterFinder); ok {
+		if err := h.AfterFind(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (obj *Profile) Save(ctx context.Context, qr queryer) error {
+func (obj Profile) mutableValues() ([]string, []any) {
+	return []string{"user_id", "bio"}, []any{obj.UserID, obj.Bio}
+}
+
+func (obj Profile) insertValues() ([]string, []any) {
+	columns, values := obj.mutableValues()
+	// the primary key is inserted when it is set, otherwise it is left to the
+	// database.
+	if zero, _ := util.IsZero(obj.ID); !zero {
+		columns = append(columns, "id")
+		values = append(values, obj.ID)
+	}
+
+	return columns, values
+}
+
+// Create inserts obj and scans the inserted row back into it. BeforeCreate is
+// called before obj is validated.
+func (obj *Profile) Create(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	if err := obj.validate(); err != nil {
 		return err
 	}
-	query := `
-		INSERT INTO profiles (user_id, bio) VALUES ($1, $2, $3)
-		ON CONFLICT(id) DO UPDATE SET user_id = $1, bio = $2, updated_at = NOW()
-		RETURNING id, user_id, bio, created_at, updated_at
-  `
 
-	row := qr.QueryRowContext(ctx, query, obj.UserID, obj.Bio)
-	if err := obj.Scan(row); err != nil {
+	columns, values := obj.insertValues()
+	q := query.InsertInto("profiles").Columns(columns...).Values(values...).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Update writes the columns of obj changed since it was scanned by its primary
+// key and scans the updated row back into it. Nothing is written when no
+// column is changed, and then AfterUpdate is not called.
+func (obj *Profile) Update(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeUpdater); ok {
+		if err := h.BeforeUpdate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
+		return err
+	}
+
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return goooerrors.Wrap(ErrPrimaryKeyMissing)
+	}
+
+	columns, values := obj.mutableValues()
+	q := query.Update("profiles")
+	changed := false
+	for i, c := range columns {
+		if obj.snapshot.Changed(c, values[i]) {
+			q.Set(c, values[i])
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	q.Set("updated_at", query.Raw("NOW()"))
+
+	q.Where(query.Eq("id", obj.ID)).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterUpdater)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return goooerrors.Wrap(ErrNotFound)
+			}
+
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterUpdate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Upsert inserts obj or, when the row conflicts on the target columns, updates
+// the existing row. The target defaults to the primary key. The create hooks
+// are called in either case.
+func (obj *Profile) Upsert(ctx context.Context, qr queryer, target ...string) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
+		return err
+	}
+
+	if len(target) == 0 {
+		target = []string{"id"}
+	}
+
+	mutable, _ := obj.mutableValues()
+	columns, values := obj.insertValues()
+	q := query.InsertInto("profiles").
+		Columns(columns...).
+		Values(values...).
+		OnConflict(target...).
+		DoUpdate(mutable...).
+		DoUpdateSet("updated_at", query.Raw("NOW()")).
+		Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Save creates obj when its primary key is zero and updates it otherwise.
+func (obj *Profile) Save(ctx context.Context, qr queryer) error {
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return obj.Create(ctx, qr)
+	}
+
+	return obj.Update(ctx, qr)
+}
+
+// Reload reads obj again by its primary key, discarding unsaved changes.
+func (obj *Profile) Reload(ctx context.Context, qr queryer) error {
+	return obj.Find(ctx, qr)
+}
+
+// PreloadProfiles loads the associations named by paths into list with one
+// query per association. Nested associations are separated by dots.
+func PreloadProfiles(ctx context.Context, qr queryer, list []Profile, paths ...string) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	for _, p := range query.Preloads(paths...) {
+		switch p.Name {
+
+		default:
+			return goooerrors.Errorf("association %s not found on Profile", p.Name)
+		}
+	}
+
+	return nil
+}
+
+func (obj *Profile) Preload(ctx context.Context, qr queryer, paths ...string) error {
+	list := []Profile{*obj}
+	if err := PreloadProfiles(ctx, qr, list, paths...); err != nil {
 		return err
 	}
 
+	*obj = list[0]
 	return nil
 }
 
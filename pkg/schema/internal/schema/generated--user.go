@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/version-1/gooo/pkg/datasource/orm"
 	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
+	"github.com/version-1/gooo/pkg/datasource/query"
 	goooerrors "github.com/version-1/gooo/pkg/errors"
 	"github.com/version-1/gooo/pkg/presenter/jsonapi"
 	"github.com/version-1/gooo/pkg/util"
@@ -17,15 +20,44 @@ func (obj User) Columns() []string {
 	return []string{"id", "username", "email", "refresh_token", "timezone", "time_diff", "created_at", "updated_at"}
 }
 
+const (
+	UserID           query.Column[int]       = "id"
+	UserUsername     query.Column[string]    = "username"
+	UserEmail        query.Column[string]    = "email"
+	UserRefreshToken query.Column[string]    = "refresh_token"
+	UserTimezone     query.Column[string]    = "timezone"
+	UserTimeDiff     query.Column[int]       = "time_diff"
+	UserCreatedAt    query.Column[time.Time] = "created_at"
+	UserUpdatedAt    query.Column[time.Time] = "updated_at"
+)
+
+var Users = query.NewScope(query.Table[User]{
+	Name:    "users",
+	Columns: User{}.Columns(),
+	Scan: func(rows query.Scanner) (User, error) {
+		obj := User{}
+		err := obj.Scan(rows)
+		return obj, err
+	},
+	NotFound: ErrNotFound,
+})
+
 func (obj *User) Scan(rows scanner) error {
 	if err := rows.Scan(&obj.ID, &obj.Username, &obj.Email, &obj.RefreshToken, &obj.Timezone, &obj.TimeDiff, &obj.CreatedAt, &obj.UpdatedAt); err != nil {
 		return err
 	}
 
+	obj.snapshot.Take(obj.Columns(), []any{obj.ID, obj.Username, obj.Email, obj.RefreshToken, obj.Timezone, obj.TimeDiff, obj.CreatedAt, obj.UpdatedAt})
+
 	return nil
 }
 
 func (obj *User) Destroy(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -35,15 +67,39 @@ func (obj *User) Destroy(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(ErrPrimaryKeyMissing)
 	}
 
+	if h, ok := any(obj).(orm.BeforeDestroyer); ok {
+		if err := h.BeforeDestroy(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	query := "DELETE FROM users WHERE id = $1"
-	if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
-		return goooerrors.Wrap(err)
+	after, hook := any(obj).(orm.AfterDestroyer)
+	write := func(qr orm.QueryRunner) error {
+		if _, err := qr.ExecContext(ctx, query, obj.ID); err != nil {
+			return goooerrors.Wrap(ormerrors.Translate(err))
+		}
+
+		if hook {
+			return after.AfterDestroy(ctx, qr)
+		}
+
+		return nil
 	}
 
-	return nil
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
 }
 
 func (obj *User) Find(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
 	zero, err := util.IsZero(obj.ID)
 	if err != nil {
 		return goooerrors.Wrap(err)
@@ -64,24 +120,282 @@ func (obj *User) Find(ctx context.Context, qr queryer) error {
 		return goooerrors.Wrap(err)
 	}
 
+	if h, ok := any(obj).(orm.AfterFinder); ok {
+		if err := h.AfterFind(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (obj *User) Save(ctx context.Context, qr queryer) error {
+func (obj User) mutableValues() ([]string, []any) {
+	return []string{"username", "email", "refresh_token", "timezone", "time_diff"}, []any{obj.Username, obj.Email, obj.RefreshToken, obj.Timezone, obj.TimeDiff}
+}
+
+func (obj User) insertValues() ([]string, []any) {
+	columns, values := obj.mutableValues()
+	// the primary key is inserted when it is set, otherwise it is left to the
+	// database.
+	if zero, _ := util.IsZero(obj.ID); !zero {
+		columns = append(columns, "id")
+		values = append(values, obj.ID)
+	}
+
+	return columns, values
+}
+
+// Create inserts obj and scans the inserted row back into it. BeforeCreate is
+// called before obj is validated.
+func (obj *User) Create(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
 	if err := obj.validate(); err != nil {
 		return err
 	}
-	query := `
-		INSERT INTO users (username, email, refresh_token, timezone, time_diff, profile, posts) VALUES ($1, $2, $3)
-		ON CONFLICT(id) DO UPDATE SET username = $1, email = $2, refresh_token = $3, timezone = $4, time_diff = $5, profile = $6, posts = $7, updated_at = NOW()
-		RETURNING id, username, email, refresh_token, timezone, time_diff, created_at, updated_at
-  `
 
-	row := qr.QueryRowContext(ctx, query, obj.Username, obj.Email, obj.RefreshToken, obj.Timezone, obj.TimeDiff, obj.Profile, obj.Posts)
-	if err := obj.Scan(row); err != nil {
+	columns, values := obj.insertValues()
+	q := query.InsertInto("users").Columns(columns...).Values(values...).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Update writes the columns of obj changed since it was scanned by its primary
+// key and scans the updated row back into it. Nothing is written when no
+// column is changed, and then AfterUpdate is not called.
+func (obj *User) Update(ctx context.Context, qr queryer) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeUpdater); ok {
+		if err := h.BeforeUpdate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
 		return err
 	}
 
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return goooerrors.Wrap(ErrPrimaryKeyMissing)
+	}
+
+	columns, values := obj.mutableValues()
+	q := query.Update("users")
+	changed := false
+	for i, c := range columns {
+		if obj.snapshot.Changed(c, values[i]) {
+			q.Set(c, values[i])
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	q.Set("updated_at", query.Raw("NOW()"))
+
+	q.Where(query.Eq("id", obj.ID)).Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterUpdater)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return goooerrors.Wrap(ErrNotFound)
+			}
+
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterUpdate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Upsert inserts obj or, when the row conflicts on the target columns, updates
+// the existing row. The target defaults to the primary key. The create hooks
+// are called in either case.
+func (obj *User) Upsert(ctx context.Context, qr queryer, target ...string) error {
+	qr, err := orm.Runner(ctx, qr)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if h, ok := any(obj).(orm.BeforeCreator); ok {
+		if err := h.BeforeCreate(ctx, qr); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.validate(); err != nil {
+		return err
+	}
+
+	if len(target) == 0 {
+		target = []string{"id"}
+	}
+
+	mutable, _ := obj.mutableValues()
+	columns, values := obj.insertValues()
+	q := query.InsertInto("users").
+		Columns(columns...).
+		Values(values...).
+		OnConflict(target...).
+		DoUpdate(mutable...).
+		DoUpdateSet("updated_at", query.Raw("NOW()")).
+		Returning(obj.Columns()...)
+	after, hook := any(obj).(orm.AfterCreator)
+	write := func(qr orm.QueryRunner) error {
+		if err := obj.Scan(q.QueryRow(ctx, qr)); err != nil {
+			return goooerrors.Wrap(err)
+		}
+
+		if hook {
+			return after.AfterCreate(ctx, qr)
+		}
+
+		return nil
+	}
+
+	if hook {
+		return orm.Atomic(ctx, qr, write)
+	}
+
+	return write(qr)
+}
+
+// Save creates obj when its primary key is zero and updates it otherwise.
+func (obj *User) Save(ctx context.Context, qr queryer) error {
+	zero, err := util.IsZero(obj.ID)
+	if err != nil {
+		return goooerrors.Wrap(err)
+	}
+
+	if zero {
+		return obj.Create(ctx, qr)
+	}
+
+	return obj.Update(ctx, qr)
+}
+
+// Reload reads obj again by its primary key, discarding unsaved changes.
+func (obj *User) Reload(ctx context.Context, qr queryer) error {
+	return obj.Find(ctx, qr)
+}
+
+// PreloadUsers loads the associations named by paths into list with one
+// query per association. Nested associations are separated by dots.
+func PreloadUsers(ctx context.Context, qr queryer, list []User, paths ...string) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	for _, p := range query.Preloads(paths...) {
+		switch p.Name {
+		case "Profile":
+			records, err := Profiles.Where(query.In("user_id", query.Keys(list, func(v User) int { return v.ID }))).All(ctx, qr)
+			if err != nil {
+				return goooerrors.Wrap(err)
+			}
+
+			if err := PreloadProfiles(ctx, qr, records, p.Nested...); err != nil {
+				return err
+			}
+
+			m := query.Group(records, func(v Profile) int { return v.UserID })
+			for i := range list {
+				if g := m[list[i].ID]; len(g) > 0 {
+					list[i].Profile = &g[0]
+				}
+			}
+		case "Posts":
+			records, err := Posts.Where(query.In("user_id", query.Keys(list, func(v User) int { return v.ID }))).All(ctx, qr)
+			if err != nil {
+				return goooerrors.Wrap(err)
+			}
+
+			if err := PreloadPosts(ctx, qr, records, p.Nested...); err != nil {
+				return err
+			}
+
+			m := query.Group(records, func(v Post) int { return v.UserID })
+			for i := range list {
+				list[i].Posts = append([]Post{}, m[list[i].ID]...)
+			}
+		case "Likes":
+			through := append([]User{}, list...)
+			nested := []string{"Posts.Likes"}
+			for _, n := range p.Nested {
+				nested = append(nested, "Posts.Likes."+n)
+			}
+
+			if err := PreloadUsers(ctx, qr, through, nested...); err != nil {
+				return err
+			}
+
+			for i := range through {
+				list[i].Likes = []Like{}
+				for _, t := range through[i].Posts {
+					list[i].Likes = append(list[i].Likes, t.Likes...)
+				}
+			}
+		default:
+			return goooerrors.Errorf("association %s not found on User", p.Name)
+		}
+	}
+
+	return nil
+}
+
+func (obj *User) Preload(ctx context.Context, qr queryer, paths ...string) error {
+	list := []User{*obj}
+	if err := PreloadUsers(ctx, qr, list, paths...); err != nil {
+		return err
+	}
+
+	*obj = list[0]
 	return nil
 }
 
@@ -96,6 +410,7 @@ func (obj *User) Assign(v User) {
 	obj.UpdatedAt = v.UpdatedAt
 	obj.Profile = v.Profile
 	obj.Posts = v.Posts
+	obj.Likes = v.Likes
 }
 
 func (obj User) validate() ormerrors.ValidationError {
@@ -124,19 +439,34 @@ func (obj User) ToJSONAPIResource() (jsonapi.Resource, jsonapi.Resources) {
 		Relationships: jsonapi.Relationships{},
 	}
 
-	ele := obj.Profile
-	if ele != nil {
-		jsonapi.HasOne(r, includes, ele, ele.ID, "profile")
+	{
+		ele := obj.Profile
+		if ele != nil {
+			jsonapi.HasOneNamed(r, includes, "profile", ele, ele.ID, "profile")
+		}
+	}
+
+	{
+		elements := []jsonapi.Resourcer{}
+		for _, ele := range obj.Posts {
+			elements = append(elements, jsonapi.Resourcer(ele))
+		}
+		jsonapi.HasManyNamed(r, includes, "posts", elements, "post", func(ri *jsonapi.ResourceIdentifier, i int) {
+			id := obj.Posts[i].ID
+			ri.ID = jsonapi.Stringify(id)
+		})
 	}
 
-	elements := []jsonapi.Resourcer{}
-	for _, ele := range obj.Posts {
-		elements = append(elements, jsonapi.Resourcer(ele))
+	{
+		elements := []jsonapi.Resourcer{}
+		for _, ele := range obj.Likes {
+			elements = append(elements, jsonapi.Resourcer(ele))
+		}
+		jsonapi.HasManyNamed(r, includes, "likes", elements, "like", func(ri *jsonapi.ResourceIdentifier, i int) {
+			id := obj.Likes[i].ID
+			ri.ID = jsonapi.Stringify(id)
+		})
 	}
-	jsonapi.HasMany(r, includes, elements, "post", func(ri *jsonapi.ResourceIdentifier, i int) {
-		id := obj.Posts[i].ID
-		ri.ID = jsonapi.Stringify(id)
-	})
 
 	return *r, *includes
 }
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -198,6 +200,66 @@ func TestResourceSerialize(t *testing.T) {
 	}
 }
 
+func TestPolymorphicResourceSerialize(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2024-08-07T01:58:13+00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := Post{ID: 4, UserID: 1, Title: "title", Body: "body", CreatedAt: now, UpdatedAt: now}
+	like := Like{ID: 7, LikeableID: 4, LikeableType: "post", CreatedAt: now, UpdatedAt: now, Likeable: post}
+
+	resource, includes := like.ToJSONAPIResource()
+	if got := resource.Relationships["likeable"]; got != (jsonapi.Relationship{Data: jsonapi.ResourceIdentifier{ID: "4", Type: "post"}}) {
+		t.Errorf("expect likeable linkage to post 4, got %v", got)
+	}
+
+	if len(includes.Data) != 1 || includes.Data[0].Type != "post" || includes.Data[0].ID != "4" {
+		t.Errorf("expect post 4 to be included, got %v", includes.Data)
+	}
+
+	like.Likeable = nil
+	resource, includes = like.ToJSONAPIResource()
+	if _, ok := resource.Relationships["likeable"]; !ok || len(includes.Data) != 0 {
+		t.Errorf("expect linkage without included resources, got %v %v", resource.Relationships, includes.Data)
+	}
+
+	u := NewUserWith(User{ID: 1, Likes: []Like{like}})
+	resource, includes = u.ToJSONAPIResource()
+	ri, ok := resource.Relationships["likes"].(jsonapi.RelationshipHasMany)
+	if !ok || len(ri.Data) != 1 || ri.Data[0].ID != "7" {
+		t.Errorf("expect linkage through likes, got %v", resource.Relationships)
+	}
+
+	if len(includes.Data) != 1 || includes.Data[0].Type != "like" {
+		t.Errorf("expect like 7 to be included, got %v", includes.Data)
+	}
+}
+
+func TestIncludeByAssociationName(t *testing.T) {
+	u := NewUserWith(User{
+		ID:      1,
+		Posts:   []Post{{ID: 10, UserID: 1}},
+		Profile: &Profile{ID: 20, UserID: 1},
+	})
+	resource, includes := u.ToJSONAPIResource()
+
+	q, err := jsonapi.ParseQuery(url.Values{"include": {"posts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, res := q.Apply([]jsonapi.Resource{resource}, includes)
+	keys := []string{}
+	for _, r := range res.Data {
+		keys = append(keys, r.Type+":"+r.ID)
+	}
+
+	if expect := []string{"post:10"}; !reflect.DeepEqual(keys, expect) {
+		t.Errorf("expect %v, got %v", expect, keys)
+	}
+}
+
 func diff(expected, got string) error {
 	line := 1
 	for i := 0; i < len(expected); i++ {
@@ -2,9 +2,12 @@ package fixtures
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	_ "github.com/lib/pq"
@@ -12,6 +15,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/version-1/gooo/pkg/datasource/logging"
 	"github.com/version-1/gooo/pkg/datasource/orm"
+	ormerrors "github.com/version-1/gooo/pkg/datasource/orm/errors"
 )
 
 func TestTransaction(t *testing.T) {
@@ -75,3 +79,323 @@ func TestTransactionRollback(t *testing.T) {
 		t.Fatalf("expected 0, but got %d", count)
 	}
 }
+
+func newTestOrm(t *testing.T, tables ...string) *orm.Orm {
+	db, err := sqlx.Connect("postgres", os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	o := orm.New(db, &logging.MockLogger{}, orm.Options{QueryLog: true})
+	for _, table := range tables {
+		if _, err := o.ExecContext(context.Background(), "DELETE FROM "+table); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return o
+}
+
+func TestCreateUpdateReload(t *testing.T) {
+	o := newTestOrm(t, "users")
+	ctx := context.Background()
+
+	u := NewUserWith(User{Username: "gooo", Email: "gooo@example.com"})
+	if err := u.Create(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.ID == 0 || u.CreatedAt.IsZero() {
+		t.Fatalf("expected the inserted row to be scanned, but got %+v", u)
+	}
+
+	u.Email = "updated@example.com"
+	if err := u.Update(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	u.Email = "discarded@example.com"
+	if err := u.Reload(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Email != "updated@example.com" {
+		t.Fatalf("expected updated@example.com, but got %s", u.Email)
+	}
+
+	missing := NewUserWith(User{ID: u.ID + 1, Username: "missing"})
+	if err := missing.Update(ctx, o); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, but got %v", err)
+	}
+}
+
+func TestUpdateChangedColumns(t *testing.T) {
+	o := newTestOrm(t, "users")
+	ctx := context.Background()
+
+	u := NewUserWith(User{Username: "gooo", Email: "gooo@example.com"})
+	if err := u.Create(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	// the row is changed by someone else after u was scanned.
+	if _, err := o.ExecContext(ctx, "UPDATE users SET timezone = 'Asia/Tokyo' WHERE id = $1", u.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	updatedAt := u.UpdatedAt
+	if err := u.Update(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	if !u.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("expected nothing to be written, but updated_at changed to %s", u.UpdatedAt)
+	}
+
+	u.Email = "updated@example.com"
+	if err := u.Update(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Timezone != "Asia/Tokyo" || u.Email != "updated@example.com" {
+		t.Fatalf("expected only email to be written, but got %+v", u)
+	}
+}
+
+// recordingQueryer records the queries it runs on a database which can not
+// be connected to.
+type recordingQueryer struct {
+	queries []string
+	db      *sql.DB
+}
+
+func newRecordingQueryer() *recordingQueryer {
+	return &recordingQueryer{db: sql.OpenDB(unreachable{})}
+}
+
+func (q *recordingQueryer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	q.queries = append(q.queries, query)
+	return q.db.QueryRowContext(ctx, query, args...)
+}
+
+func (q *recordingQueryer) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	q.queries = append(q.queries, query)
+	return q.db.QueryContext(ctx, query, args...)
+}
+
+func (q *recordingQueryer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	q.queries = append(q.queries, query)
+	return q.db.ExecContext(ctx, query, args...)
+}
+
+type unreachable struct{}
+
+func (unreachable) Connect(ctx context.Context) (driver.Conn, error) {
+	return nil, errors.New("unreachable")
+}
+
+func (unreachable) Driver() driver.Driver { return nil }
+
+// scanned takes the values of the fields as scanned.
+type scanned struct{}
+
+func (scanned) Scan(dest ...any) error { return nil }
+
+func TestUpdateSetsChangedColumns(t *testing.T) {
+	ctx := context.Background()
+	p := NewPostWith(Post{ID: 1, UserID: 1, Title: "title", Body: "body"})
+	if err := p.Scan(scanned{}); err != nil {
+		t.Fatal(err)
+	}
+
+	qr := newRecordingQueryer()
+	if err := p.Update(ctx, qr); err != nil || len(qr.queries) > 0 {
+		t.Fatalf("expected nothing to be written, got %v %v", err, qr.queries)
+	}
+
+	p.Title = "updated"
+	p.Update(ctx, qr)
+	if len(qr.queries) != 1 {
+		t.Fatalf("expected 1 query, got %v", qr.queries)
+	}
+
+	set, _, _ := strings.Cut(qr.queries[0], "WHERE")
+	if !strings.Contains(set, "title") || strings.Contains(set, "user_id") || strings.Contains(set, "body") {
+		t.Errorf("expected only title to be set, got %s", qr.queries[0])
+	}
+}
+
+func TestUniqueViolation(t *testing.T) {
+	o := newTestOrm(t, "users")
+	ctx := context.Background()
+
+	u := NewUserWith(User{Username: "gooo", Email: "gooo@example.com"})
+	if err := u.Create(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewUserWith(User{Username: "gooo", Email: "other@example.com"})
+	var unique ormerrors.UniqueViolationError
+	if err := v.Create(ctx, o); !errors.As(err, &unique) {
+		t.Fatalf("expected UniqueViolationError, but got %v", err)
+	}
+
+	if strings.Join(unique.Columns, ",") != "username" {
+		t.Fatalf("expected the username column, but got %v", unique.Columns)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	o := newTestOrm(t, "users")
+	ctx := context.Background()
+
+	u := NewUserWith(User{Username: "gooo", Email: "gooo@example.com"})
+	if err := u.Upsert(ctx, o, "username"); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewUserWith(User{Username: "gooo", Email: "upserted@example.com"})
+	if err := v.Upsert(ctx, o, "username"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.ID != u.ID || v.Email != "upserted@example.com" {
+		t.Fatalf("expected user %d to be updated, but got %+v", u.ID, v)
+	}
+
+	count, err := Users.Count(ctx, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1, but got %d", count)
+	}
+}
+
+var profileHooks []string
+
+func (p *Profile) BeforeCreate(ctx context.Context, qr orm.QueryRunner) error {
+	if p.UserID == 0 {
+		return errors.New("user_id is required")
+	}
+
+	if p.Bio == "" {
+		p.Bio = "no bio"
+	}
+
+	profileHooks = append(profileHooks, "BeforeCreate")
+	return nil
+}
+
+func (p *Profile) AfterCreate(ctx context.Context, qr orm.QueryRunner) error {
+	if p.Bio == "rollback" {
+		return errors.New("rollback")
+	}
+
+	profileHooks = append(profileHooks, "AfterCreate")
+	return nil
+}
+
+func (p *Profile) BeforeUpdate(ctx context.Context, qr orm.QueryRunner) error {
+	profileHooks = append(profileHooks, "BeforeUpdate")
+	return nil
+}
+
+func (p *Profile) AfterUpdate(ctx context.Context, qr orm.QueryRunner) error {
+	profileHooks = append(profileHooks, "AfterUpdate")
+	return nil
+}
+
+func (p *Profile) BeforeDestroy(ctx context.Context, qr orm.QueryRunner) error {
+	profileHooks = append(profileHooks, "BeforeDestroy")
+	return nil
+}
+
+func (p *Profile) AfterDestroy(ctx context.Context, qr orm.QueryRunner) error {
+	profileHooks = append(profileHooks, "AfterDestroy")
+	return nil
+}
+
+func (p *Profile) AfterFind(ctx context.Context, qr orm.QueryRunner) error {
+	profileHooks = append(profileHooks, "AfterFind")
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	o := newTestOrm(t, "profiles")
+	ctx := context.Background()
+	profileHooks = []string{}
+
+	if err := NewProfile().Create(ctx, o); err == nil {
+		t.Fatal("expected BeforeCreate to abort the insert")
+	}
+
+	err := o.Transaction(ctx, func(e *orm.Executor) error {
+		p := NewProfileWith(Profile{UserID: 1})
+		if err := p.Create(ctx, e); err != nil {
+			return err
+		}
+
+		if p.Bio != "no bio" {
+			t.Errorf("expected the bio set by BeforeCreate, but got %s", p.Bio)
+		}
+
+		p.Bio = "updated"
+		if err := p.Update(ctx, e); err != nil {
+			return err
+		}
+
+		if err := p.Reload(ctx, e); err != nil {
+			return err
+		}
+
+		if _, err := Profiles.All(ctx, e); err != nil {
+			return err
+		}
+
+		return p.Destroy(ctx, e)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"BeforeCreate", "AfterCreate",
+		"BeforeUpdate", "AfterUpdate",
+		"AfterFind", "AfterFind",
+		"BeforeDestroy", "AfterDestroy",
+	}
+	if strings.Join(profileHooks, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expected %v, but got %v", expect, profileHooks)
+	}
+}
+
+func TestAfterHookRollback(t *testing.T) {
+	o := newTestOrm(t, "profiles")
+	ctx := context.Background()
+
+	if err := NewProfileWith(Profile{UserID: 1, Bio: "rollback"}).Create(ctx, o); err == nil {
+		t.Fatal("expected AfterCreate to fail")
+	}
+
+	err := o.Transaction(ctx, func(e *orm.Executor) error {
+		if err := NewProfileWith(Profile{UserID: 1, Bio: "rollback"}).Create(ctx, e); err == nil {
+			t.Error("expected AfterCreate to fail")
+		}
+
+		return NewProfileWith(Profile{UserID: 2}).Create(ctx, e)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := o.QueryRowContext(ctx, "SELECT count(*) FROM profiles WHERE bio = 'rollback';").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expected the inserts to be rolled back with AfterCreate, but got %d rows", count)
+	}
+}
@@ -1,8 +1,15 @@
 package fixtures
 
-import "time"
+import (
+	"time"
+
+	"github.com/version-1/gooo/pkg/datasource/orm"
+	"github.com/version-1/gooo/pkg/presenter/jsonapi"
+)
 
 type User struct {
+	snapshot orm.Snapshot
+
 	ID           int       `json:"id" gooo:"primary_key,immutable"`
 	Username     string    `json:"username" gooo:"unique"`
 	Email        string    `json:"email"`
@@ -14,9 +21,12 @@ type User struct {
 
 	Profile *Profile `json:"profile" gooo:"association"`
 	Posts   []Post   `json:"posts" gooo:"association"`
+	Likes   []Like   `json:"likes" gooo:"association,through=posts"`
 }
 
 type Post struct {
+	snapshot orm.Snapshot
+
 	ID        int       `json:"id" gooo:"primary_key,immutable"`
 	UserID    int       `json:"user_id" gooo:"index"`
 	Title     string    `json:"title"`
@@ -24,11 +34,13 @@ type Post struct {
 	CreatedAt time.Time `json:"created_at" gooo:"immutable"`
 	UpdatedAt time.Time `json:"updated_at" gooo:"immutable"`
 
-	User  User   `json:"user" gooo:"association"`
-	Likes []Like `json:"likes" gooo:"association"`
+	User  User   `json:"user" gooo:"association,foreign_key=user_id"`
+	Likes []Like `json:"likes" gooo:"association,polymorphic=likeable"`
 }
 
 type Profile struct {
+	snapshot orm.Snapshot
+
 	ID        int       `json:"id" gooo:"primary_key,immutable"`
 	UserID    int       `json:"user_id" gooo:"index"`
 	Bio       string    `json:"bio" gooo:"type=text"`
@@ -37,9 +49,13 @@ type Profile struct {
 }
 
 type Like struct {
+	snapshot orm.Snapshot
+
 	ID           int       `json:"id" gooo:"primary_key,immutable"`
 	LikeableID   int       `json:"likeable_id" gooo:"index"`
 	LikeableType string    `json:"likeable_type" gooo:"index"`
 	CreatedAt    time.Time `json:"created_at" gooo:"immutable"`
 	UpdatedAt    time.Time `json:"updated_at" gooo:"immutable"`
+
+	Likeable jsonapi.Resourcer `json:"-" gooo:"association,polymorphic=likeable"`
 }
@@ -4,6 +4,8 @@ import (
 	"go/ast"
 	"go/token"
 	"os"
+	gopath "path"
+	"strconv"
 
 	goparser "go/parser"
 
@@ -12,6 +14,10 @@ import (
 	"github.com/version-1/gooo/pkg/strings"
 )
 
+// ormPackage is the import path of the package declaring Snapshot, the type
+// of the field holding the snapshot of a schema.
+const ormPackage = "github.com/version-1/gooo/pkg/datasource/orm"
+
 type parser struct{}
 
 func NewParser() *parser {
@@ -31,6 +37,7 @@ func (p parser) Parse(path string) ([]Schema, error) {
 		return list, errors.Wrap(err)
 	}
 
+	orm := importName(node, ormPackage)
 	m := map[string]*Schema{}
 	ast.Inspect(node, func(n ast.Node) bool {
 		if t, ok := n.(*ast.TypeSpec); ok {
@@ -45,6 +52,15 @@ func (p parser) Parse(path string) ([]Schema, error) {
 		}
 
 		if field, ok := n.(*ast.Field); ok {
+			if isSnapshot(field, orm) && len(list) > 0 {
+				name := "Snapshot"
+				if len(field.Names) > 0 {
+					name = field.Names[0].Name
+				}
+				list[len(list)-1].SnapshotField = name
+				return true
+			}
+
 			if field.Tag != nil {
 				typeName, typeElementExpr := valuetype.ResolveTypeName(field.Type)
 				list[len(list)-1].AddFields(Field{
@@ -64,18 +80,149 @@ func (p parser) Parse(path string) ([]Schema, error) {
 		for j := range list[i].Fields {
 			f := list[i].Fields[j]
 			if f.IsAssociation() {
-				schema, ok := m[f.TypeElementExpr]
-				if !ok {
-					return list, errors.Errorf("schema %s not found on association", f.TypeElementExpr)
+				a, err := resolveAssociation(&list[i], f, m)
+				if err != nil {
+					return list, err
 				}
 
-				list[i].Fields[j].Association = &Association{
-					Schema: schema,
-					Slice:  f.IsSlice(),
-				}
+				list[i].Fields[j].Association = a
+			}
+		}
+	}
+
+	for i := range list {
+		for j := range list[i].Fields {
+			a := list[i].Fields[j].Association
+			if a != nil && a.Polymorphic != "" && a.BelongsTo {
+				a.PolymorphicTypes = polymorphicTypes(list, list[i].Name, a.Polymorphic)
 			}
 		}
 	}
 
 	return list, nil
 }
+
+// polymorphicTypes returns the schemas having the polymorphic has-many
+// association name of the schema owner.
+func polymorphicTypes(list []Schema, owner, name string) []PolymorphicType {
+	types := []PolymorphicType{}
+	for i := range list {
+		for _, f := range list[i].Fields {
+			if f.IsAssociation() && f.Tag.Polymorphic == name && f.IsSlice() && f.TypeElementExpr == owner {
+				types = append(types, PolymorphicType{
+					Schema:     list[i].Name,
+					PrimaryKey: list[i].PrimaryKey(),
+				})
+				break
+			}
+		}
+	}
+
+	return types
+}
+
+func resolveAssociation(owner *Schema, f Field, m map[string]*Schema) (*Association, error) {
+	a := &Association{
+		Slice:       f.IsSlice(),
+		ForeignKey:  f.Tag.ForeignKey,
+		Polymorphic: f.Tag.Polymorphic,
+		Through:     f.Tag.Through,
+	}
+
+	if a.Polymorphic != "" && !a.Slice {
+		a.BelongsTo = true
+		a.ForeignKey = a.Polymorphic + "_id"
+		for _, col := range []string{a.ForeignKey, a.Polymorphic + "_type"} {
+			if _, ok := owner.FieldByColumn(col); !ok {
+				return nil, errors.Errorf("column %s not found on polymorphic association %s.%s", col, owner.Name, f.Name)
+			}
+		}
+
+		return a, nil
+	}
+
+	schema, ok := m[f.TypeElementExpr]
+	if !ok {
+		return nil, errors.Errorf("schema %s not found on association", f.TypeElementExpr)
+	}
+	a.Schema = schema
+
+	if a.Through != "" {
+		through, ok := owner.FieldByColumn(a.Through)
+		if !ok || !through.IsAssociation() {
+			return nil, errors.Errorf("association %s not found on %s.%s through", a.Through, owner.Name, f.Name)
+		}
+
+		if !a.Slice {
+			return nil, errors.Errorf("association %s.%s through %s must be a slice", owner.Name, f.Name, a.Through)
+		}
+
+		if s, ok := m[through.TypeElementExpr]; ok {
+			for _, tf := range s.Fields {
+				if tf.IsAssociation() && tf.TypeElementExpr == f.TypeElementExpr {
+					a.ThroughTarget = tf.Name
+					break
+				}
+			}
+		}
+
+		if a.ThroughTarget == "" {
+			return nil, errors.Errorf("association of %s not found on %s through %s.%s", f.TypeElementExpr, through.TypeElementExpr, owner.Name, f.Name)
+		}
+
+		return a, nil
+	}
+
+	switch {
+	case a.Polymorphic != "":
+		a.ForeignKey = a.Polymorphic + "_id"
+	case a.ForeignKey != "":
+		_, a.BelongsTo = owner.FieldByColumn(a.ForeignKey)
+	default:
+		// has-one or has-many keyed by <owner>_id, or else belongs-to keyed by
+		// <field>_id. Otherwise the foreign key is left empty.
+		if fk := strings.ToSnakeCase(owner.Name) + "_id"; hasColumn(schema, fk) {
+			a.ForeignKey = fk
+		} else if fk := strings.ToSnakeCase(f.Name) + "_id"; !a.Slice && hasColumn(owner, fk) {
+			a.ForeignKey = fk
+			a.BelongsTo = true
+		}
+	}
+
+	return a, nil
+}
+
+func hasColumn(s *Schema, col string) bool {
+	_, ok := s.FieldByColumn(col)
+	return ok
+}
+
+// importName returns the name path is imported as in file, or an empty string
+// when it is not imported.
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != path {
+			continue
+		}
+
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+
+		return gopath.Base(path)
+	}
+
+	return ""
+}
+
+// isSnapshot reports whether the type of f is Snapshot of the orm package
+// imported as orm.
+func isSnapshot(f *ast.Field, orm string) bool {
+	t, ok := f.Type.(*ast.SelectorExpr)
+	if !ok || orm == "" {
+		return false
+	}
+
+	x, ok := t.X.(*ast.Ident)
+	return ok && x.Name == orm && t.Sel.Name == "Snapshot"
+}
@@ -1,6 +1,8 @@
 package schema
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -15,8 +17,9 @@ func TestParser_Parse(t *testing.T) {
 	}
 
 	profileSchema := &Schema{
-		Name:      "Profile",
-		TableName: "profiles",
+		Name:          "Profile",
+		TableName:     "profiles",
+		SnapshotField: "snapshot",
 		Fields: []Field{
 			{
 				Name:            "ID",
@@ -68,8 +71,9 @@ func TestParser_Parse(t *testing.T) {
 	}
 
 	userSchema := Schema{
-		Name:      "User",
-		TableName: "users",
+		Name:          "User",
+		TableName:     "users",
+		SnapshotField: "snapshot",
 		Fields: []Field{
 			{
 				Name:            "ID",
@@ -152,8 +156,9 @@ func TestParser_Parse(t *testing.T) {
 			Association: true,
 		},
 		Association: &Association{
-			Slice:  false,
-			Schema: profileSchema,
+			Slice:      false,
+			ForeignKey: "user_id",
+			Schema:     profileSchema,
 		},
 	}
 
@@ -166,10 +171,12 @@ func TestParser_Parse(t *testing.T) {
 			Association: true,
 		},
 		Association: &Association{
-			Slice: true,
+			Slice:      true,
+			ForeignKey: "user_id",
 			Schema: &Schema{
-				Name:      "Post",
-				TableName: "posts",
+				Name:          "Post",
+				TableName:     "posts",
+				SnapshotField: "snapshot",
 				Fields: []Field{
 					{
 						Name:            "ID",
@@ -230,14 +237,18 @@ func TestParser_Parse(t *testing.T) {
 						Type:            valuetype.FieldValueType("User"),
 						TypeElementExpr: "User",
 						Tag: FieldTag{
-							Raw:         []string{"association"},
+							Raw:         []string{"association", "foreign_key=user_id"},
 							Association: true,
+							ForeignKey:  "user_id",
 						},
 						Association: &Association{
-							Slice: false,
+							Slice:      false,
+							BelongsTo:  true,
+							ForeignKey: "user_id",
 							Schema: &Schema{
-								Name:      "User",
-								TableName: "users",
+								Name:          "User",
+								TableName:     "users",
+								SnapshotField: "snapshot",
 								Fields: []Field{
 									{
 										Name:            "ID",
@@ -330,14 +341,18 @@ func TestParser_Parse(t *testing.T) {
 						Type:            valuetype.Slice(valuetype.FieldValueType("Like")),
 						TypeElementExpr: "Like",
 						Tag: FieldTag{
-							Raw:         []string{"association"},
+							Raw:         []string{"association", "polymorphic=likeable"},
 							Association: true,
+							Polymorphic: "likeable",
 						},
 						Association: &Association{
-							Slice: true,
+							Slice:       true,
+							ForeignKey:  "likeable_id",
+							Polymorphic: "likeable",
 							Schema: &Schema{
-								Name:      "Like",
-								TableName: "likes",
+								Name:          "Like",
+								TableName:     "likes",
+								SnapshotField: "snapshot",
 								Fields: []Field{
 									{
 										Name:            "ID",
@@ -385,6 +400,22 @@ func TestParser_Parse(t *testing.T) {
 											Immutable: true,
 										},
 									},
+									{
+										Name:            "Likeable",
+										Type:            valuetype.FieldValueType("jsonapi.Resourcer"),
+										TypeElementExpr: "jsonapi.Resourcer",
+										Tag: FieldTag{
+											Raw:         []string{"association", "polymorphic=likeable"},
+											Association: true,
+											Polymorphic: "likeable",
+										},
+										Association: &Association{
+											BelongsTo:        true,
+											ForeignKey:       "likeable_id",
+											Polymorphic:      "likeable",
+											PolymorphicTypes: []PolymorphicType{{Schema: "Post", PrimaryKey: "ID"}},
+										},
+									},
 								},
 							},
 						},
@@ -417,10 +448,39 @@ func TestParser_Parse(t *testing.T) {
 	}
 
 	opt := cmp.FilterValues(func(x, y *Schema) bool {
-		return x.Name == "User" || y.Name == "User"
+		return (x != nil && x.Name == "User") || (y != nil && y.Name == "User")
 	}, cmp.Ignore())
 
 	if diff := cmp.Diff(postsField, posts, opt); diff != "" {
 		t.Errorf("postsField mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestParser_ParseWithoutForeignKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.go")
+	src := `package fixtures
+
+type Author struct {
+	ID     int    ` + "`" + `gooo:"primary_key"` + "`" + `
+	Essays []Essay ` + "`" + `gooo:"association"` + "`" + `
+}
+
+type Essay struct {
+	ID    int    ` + "`" + `gooo:"primary_key"` + "`" + `
+	Title string
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := NewParser().Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := list[0].Fields[1].Association
+	if a == nil || a.Schema == nil || a.Schema.Name != "Essay" || a.ForeignKey != "" || a.BelongsTo {
+		t.Errorf("expected the association to Essay without foreign key, got %+v", a)
+	}
+}
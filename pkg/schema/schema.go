@@ -26,6 +26,10 @@ type Schema struct {
 	Name      string
 	TableName string
 	Fields    []Field
+	// SnapshotField is the orm.Snapshot field of the schema, which the
+	// generator adds when the schema has none. The generated Update writes
+	// only the columns changed since it was taken.
+	SnapshotField string
 }
 
 type SchemaType struct {
@@ -40,6 +44,10 @@ func (s Schema) GetName() string {
 	return s.Name
 }
 
+func (s Schema) GetSnapshotField() string {
+	return s.SnapshotField
+}
+
 func (s Schema) GetTableName() string {
 	return s.TableName
 }
@@ -99,7 +107,7 @@ func (s Schema) SetClause() []string {
 	return placeholders
 }
 
-func (s *Schema) MutablePlaceholders() []string {
+func (s Schema) MutablePlaceholders() []string {
 	placeholders := []string{}
 	index := 1
 	for i := range s.Fields {
@@ -193,6 +201,16 @@ func (s Schema) ColumnFieldNames() []string {
 	return fields
 }
 
+// ColumnFieldTypes returns the types of ColumnFieldNames.
+func (s Schema) ColumnFieldTypes() []string {
+	types := []string{}
+	for _, f := range s.ColumnFields() {
+		types = append(types, f.Type.String())
+	}
+
+	return types
+}
+
 func (s Schema) Columns() []string {
 	fields := []string{}
 	for _, f := range s.ColumnFields() {
@@ -248,20 +266,87 @@ func (s Schema) AssociationFieldIdents() []renderer.AssociationIdent {
 
 			typeName := gooostrings.ToSnakeCase(t.String())
 			primaryKey := field.AssociationPrimaryKey()
-			idents = append(idents, renderer.AssociationIdent{
+			ident := renderer.AssociationIdent{
 				PrimaryKey:      primaryKey,
 				FieldName:       field.Name,
 				TypeName:        typeName,
 				TypeElementExpr: field.TypeElementExpr,
 				Slice:           ok,
 				Ref:             field.IsRef(),
-			})
+			}
+
+			if a := field.Association; a != nil {
+				ident.ForeignKey = a.ForeignKey
+				ident.Polymorphic = a.Polymorphic
+				ident.Through = a.Through
+				if a.BelongsTo {
+					fk, _ := s.FieldByColumn(a.ForeignKey)
+					ident.ForeignKeyFieldName = fk.Name
+					ident.KeyType = fk.Type.String()
+				} else if a.Schema != nil {
+					ident.KeyType = s.primaryKeyType()
+					if fk, ok := a.Schema.FieldByColumn(a.ForeignKey); ok {
+						ident.AssociatedForeignKeyFieldName = fk.Name
+					}
+				}
+
+				if a.Polymorphic != "" && a.BelongsTo {
+					pt, _ := s.FieldByColumn(a.Polymorphic + "_type")
+					ident.PolymorphicTypeFieldName = pt.Name
+				}
+
+				for _, t := range a.PolymorphicTypes {
+					ident.PolymorphicTypes = append(ident.PolymorphicTypes, renderer.PolymorphicIdent{
+						TypeElementExpr: t.Schema,
+						TypeName:        gooostrings.ToSnakeCase(t.Schema),
+						PrimaryKey:      t.PrimaryKey,
+					})
+				}
+
+				if through, ok := s.FieldByColumn(a.Through); ok && through.Association != nil {
+					ident.ThroughFieldName = through.Name
+					ident.ThroughSlice = through.IsSlice()
+					ident.ThroughRef = through.IsRef()
+					ident.ThroughTarget = a.ThroughTarget
+					if ts := through.Association.Schema; ts != nil {
+						for _, tf := range ts.Fields {
+							if tf.Name == a.ThroughTarget {
+								ident.ThroughTargetSlice = tf.IsSlice()
+								ident.ThroughTargetRef = tf.IsRef()
+							}
+						}
+					}
+				}
+			}
+
+			idents = append(idents, ident)
 		}
 	}
 
 	return idents
 }
 
+// FieldByColumn returns the field whose column name is col.
+func (s Schema) FieldByColumn(col string) (Field, bool) {
+	for i := range s.Fields {
+		if s.Fields[i].ColumnName() == col {
+			return s.Fields[i], true
+		}
+	}
+
+	return Field{}, false
+}
+
+func (s Schema) primaryKeyType() string {
+	for i := range s.Fields {
+		if s.Fields[i].Tag.PrimaryKey {
+			return s.Fields[i].Type.String()
+		}
+	}
+
+	return ""
+}
+
 func (s Schema) PrimaryKey() string {
 	for i := range s.Fields {
 		if s.Fields[i].Tag.PrimaryKey {
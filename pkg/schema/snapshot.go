@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"sort"
+
+	"github.com/version-1/gooo/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// snapshotField is the name of the orm.Snapshot field added to the schemas
+// which do not declare one.
+const snapshotField = "snapshot"
+
+// addSnapshotFields adds an orm.Snapshot field to every struct of src which
+// has none, so that every model gets dirty tracking, and imports the orm
+// package when needed. src is returned as is when nothing is added.
+func addSnapshotFields(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	node, err := goparser.ParseFile(fset, "", src, goparser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	orm := importName(node, ormPackage)
+	name := orm
+	if name == "" {
+		name = "orm"
+	}
+
+	offsets := []int{}
+	var inspectErr error
+	ast.Inspect(node, func(n ast.Node) bool {
+		t, ok := n.(*ast.TypeSpec)
+		if !ok || inspectErr != nil {
+			return inspectErr == nil
+		}
+
+		st, ok := t.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+
+		for _, f := range st.Fields.List {
+			if isSnapshot(f, orm) {
+				return false
+			}
+
+			for _, id := range f.Names {
+				if id.Name == snapshotField {
+					inspectErr = errors.Errorf("field %s of %s is not an orm.Snapshot", snapshotField, t.Name.Name)
+					return false
+				}
+			}
+		}
+
+		offsets = append(offsets, fset.Position(st.Fields.Opening).Offset+1)
+		return false
+	})
+	if inspectErr != nil {
+		return nil, inspectErr
+	}
+
+	if len(offsets) == 0 {
+		return src, nil
+	}
+
+	// insert from the end so that the offsets before stay valid.
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	out := append([]byte{}, src...)
+	for _, o := range offsets {
+		field := []byte("\n" + snapshotField + " " + name + ".Snapshot\n")
+		out = append(out[:o], append(field, out[o:]...)...)
+	}
+
+	if orm == "" {
+		fset = token.NewFileSet()
+		node, err := goparser.ParseFile(fset, "", out, goparser.ParseComments)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		astutil.AddImport(fset, node, ormPackage)
+		buf := &bytes.Buffer{}
+		if err := format.Node(buf, fset, node); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		out = buf.Bytes()
+	}
+
+	out, err = format.Source(out)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAddSnapshotFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		expect string
+	}{
+		{
+			name: "adds the field and the import",
+			src: `package fixtures
+
+import "time"
+
+type User struct {
+	ID        int       ` + "`" + `gooo:"primary_key"` + "`" + `
+	CreatedAt time.Time
+}
+`,
+			expect: `package fixtures
+
+import (
+	"github.com/version-1/gooo/pkg/datasource/orm"
+	"time"
+)
+
+type User struct {
+	snapshot orm.Snapshot
+
+	ID        int ` + "`" + `gooo:"primary_key"` + "`" + `
+	CreatedAt time.Time
+}
+`,
+		},
+		{
+			name: "resolves the import name",
+			src: `package fixtures
+
+import gooo "github.com/version-1/gooo/pkg/datasource/orm"
+
+type User struct {
+	state gooo.Snapshot
+
+	ID int ` + "`" + `gooo:"primary_key"` + "`" + `
+}
+
+type Post struct {
+	ID int ` + "`" + `gooo:"primary_key"` + "`" + `
+}
+`,
+			expect: `package fixtures
+
+import gooo "github.com/version-1/gooo/pkg/datasource/orm"
+
+type User struct {
+	state gooo.Snapshot
+
+	ID int ` + "`" + `gooo:"primary_key"` + "`" + `
+}
+
+type Post struct {
+	snapshot gooo.Snapshot
+
+	ID int ` + "`" + `gooo:"primary_key"` + "`" + `
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := addSnapshotFields([]byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.expect, string(out)); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	src := `package fixtures
+
+type User struct {
+	snapshot string
+}
+`
+	if _, err := addSnapshotFields([]byte(src)); err == nil {
+		t.Error("expected an error for a snapshot field of another type")
+	}
+}
@@ -1,3 +1,6 @@
 DROP TABLE IF EXISTS test_transaction;
 DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS profiles;
+DROP TABLE IF EXISTS likes;
 DROP TABLE IF EXISTS seeder_users;